@@ -0,0 +1,75 @@
+// Package attest holds the trust material used to verify the signed identity documents served by
+// some providers' metadata services, which metadata served by anyone else on the network can't forge.
+//
+// The trust material is read from a filesystem with one directory per provider, e.g. aws/us-east-1.pem,
+// azure/roots.pem or gcp/jwks.json.
+// An embedded copy is used unless another one is attached to the context with WithTrust.
+package attest
+
+import (
+	"context"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed trust
+var embedded embed.FS
+
+type trustKey struct{}
+
+// WithTrust returns a context that verifies identity documents with the trust material in fsys.
+func WithTrust(ctx context.Context, fsys fs.FS) context.Context {
+	return context.WithValue(ctx, trustKey{}, fsys)
+}
+
+// Trust returns the trust material attached to the context, or the embedded trust material.
+func Trust(ctx context.Context) fs.FS {
+	if fsys, ok := ctx.Value(trustKey{}).(fs.FS); ok && fsys != nil {
+		return fsys
+	}
+
+	fsys, err := fs.Sub(embedded, "trust")
+	if err != nil {
+		// The trust directory is always embedded.
+		panic(err)
+	}
+
+	return fsys
+}
+
+// ReadCertificates reads the PEM-encoded certificates in the named file. It returns an error wrapping
+// fs.ErrNotExist if the file doesn't exist.
+func ReadCertificates(fsys fs.FS, name string) ([]*x509.Certificate, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, parseErr := x509.ParseCertificate(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s: %w", name, parseErr)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New(name + ": no certificates found")
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,87 @@
+package attest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func createCertificate(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestTrust(t *testing.T) {
+	if _, err := fs.Stat(Trust(context.Background()), "aws"); err != nil {
+		t.Errorf("Trust() without trust material attached doesn't contain the embedded aws directory: %v", err)
+	}
+
+	fsys := fstest.MapFS{"aws/us-east-1.pem": {}}
+	if _, err := fs.Stat(Trust(WithTrust(context.Background(), fsys)), "aws/us-east-1.pem"); err != nil {
+		t.Errorf("Trust() doesn't return the attached trust material: %v", err)
+	}
+}
+
+func TestReadCertificates(t *testing.T) {
+	cert := createCertificate(t)
+	fsys := fstest.MapFS{
+		"single.pem":  {Data: cert},
+		"bundle.pem":  {Data: append(append([]byte("# comment\n"), cert...), cert...)},
+		"empty.pem":   {Data: []byte("not a certificate\n")},
+		"invalid.pem": {Data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("invalid")})},
+	}
+
+	tests := []struct {
+		name          string
+		file          string
+		expectedCount int
+		expectedErr   bool
+	}{
+		{name: "Single certificate", file: "single.pem", expectedCount: 1},
+		{name: "Certificate bundle", file: "bundle.pem", expectedCount: 2},
+		{name: "No certificates", file: "empty.pem", expectedErr: true},
+		{name: "Invalid certificate", file: "invalid.pem", expectedErr: true},
+		{name: "Missing file", file: "missing.pem", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certs, err := ReadCertificates(fsys, tt.file)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("ReadCertificates(%q) error = %v; want error %v", tt.file, err, tt.expectedErr)
+			}
+			if len(certs) != tt.expectedCount {
+				t.Errorf("ReadCertificates(%q) = %d certificates; want %d", tt.file, len(certs), tt.expectedCount)
+			}
+		})
+	}
+
+	if _, err := ReadCertificates(fsys, "missing.pem"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadCertificates() error for a missing file = %v; want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,298 @@
+// Package attesttest provides locally generated keys and certificates, and signers for the documents
+// verified by package attest, so that attestation can be tested offline.
+package attesttest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Identity is a test certificate and its private key.
+type Identity struct {
+	Cert *x509.Certificate
+	Key  *rsa.PrivateKey
+	// URL is where the certificate is published. The certificates it issues afterwards point to it.
+	URL string
+}
+
+// PEM returns the PEM-encoded certificate.
+func (i *Identity) PEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: i.Cert.Raw})
+}
+
+// NewCA returns a self-signed certificate authority.
+func NewCA(t testing.TB, name string) *Identity {
+	t.Helper()
+
+	return create(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+}
+
+// Issue returns a certificate for the DNS names, signed by the certificate authority. Without DNS names,
+// it returns an intermediate certificate authority.
+func (i *Identity) Issue(t testing.TB, dnsNames ...string) *Identity {
+	t.Helper()
+
+	if len(dnsNames) == 0 {
+		return create(t, &x509.Certificate{
+			Subject:               pkix.Name{CommonName: i.Cert.Subject.CommonName + " intermediate"},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}, i)
+	}
+
+	return create(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, i)
+}
+
+var serial atomic.Int64
+
+func create(t testing.TB, template *x509.Certificate, issuer *Identity) *Identity {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template.SerialNumber = big.NewInt(serial.Add(1))
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.Cert, issuer.Key
+		if issuer.URL != "" {
+			template.IssuingCertificateURL = []string{issuer.URL}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+
+	return &Identity{Cert: cert, Key: key}
+}
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSHA256        = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidRSA           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+)
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerialNumber
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      encapsulatedContentInfo
+	Certificates     asn1.RawValue
+	SignerInfos      []signerInfo `asn1:"set"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+// SignPKCS7 returns a DER-encoded PKCS #7 SignedData structure embedding the content, signed with SHA-256
+// and RSA by the signer over authenticated attributes. The signer's certificate and chain are included.
+func SignPKCS7(t testing.TB, content []byte, signer *Identity, chain ...*Identity) []byte {
+	t.Helper()
+
+	digest := sha256.Sum256(content)
+	attrs := []attribute{
+		{Type: oidContentType, Values: set(t, mustMarshal(t, oidData))},
+		{Type: oidMessageDigest, Values: set(t, mustMarshal(t, digest[:]))},
+	}
+
+	// DER sorts the elements of a SET OF.
+	encoded := make([][]byte, len(attrs))
+	for i, attr := range attrs {
+		encoded[i] = mustMarshal(t, attr)
+	}
+	sort.Slice(encoded, func(i, j int) bool { return string(encoded[i]) < string(encoded[j]) })
+
+	var body []byte
+	for _, e := range encoded {
+		body = append(body, e...)
+	}
+	signedAttrs := mustMarshal(t, asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: body})
+
+	attrDigest := sha256.Sum256(signedAttrs)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, signer.Key, crypto.SHA256, attrDigest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	certs := signer.Cert.Raw
+	for _, c := range chain {
+		certs = append(append([]byte(nil), certs...), c.Cert.Raw...)
+	}
+
+	sd := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{{Algorithm: oidSHA256}},
+		ContentInfo:      encapsulatedContentInfo{ContentType: oidData, Content: content},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs},
+		SignerInfos: []signerInfo{{
+			Version: 1,
+			IssuerAndSerialNumber: issuerAndSerialNumber{
+				Issuer:       asn1.RawValue{FullBytes: signer.Cert.RawIssuer},
+				SerialNumber: signer.Cert.SerialNumber,
+			},
+			DigestAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256},
+			// The attributes are encoded with an implicit [0] tag, but signed as a SET OF.
+			AuthenticatedAttributes:   asn1.RawValue{FullBytes: append([]byte{0xa0}, signedAttrs[1:]...)},
+			DigestEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidRSA},
+			EncryptedDigest:           signature,
+		}},
+	}
+
+	return mustMarshal(t, contentInfo{
+		ContentType: oidSignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: mustMarshal(t, sd)},
+	})
+}
+
+func set(t testing.TB, value []byte) asn1.RawValue {
+	t.Helper()
+
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: value}
+}
+
+func mustMarshal(t testing.TB, value any) []byte {
+	t.Helper()
+
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		t.Fatalf("Failed to marshal %T: %v", value, err)
+	}
+
+	return der
+}
+
+// JWKS returns a JSON Web Key Set of the public keys, by key ID.
+func JWKS(t testing.TB, keys map[string]*rsa.PrivateKey) []byte {
+	t.Helper()
+
+	type jwk struct {
+		Kty string `json:"kty"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Alg: "RS256",
+			Use: "sig",
+			Kid: kid,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	content, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to marshal key set: %v", err)
+	}
+
+	return content
+}
+
+// SignJWT returns a JSON Web Token of the claims, signed with RS256 by the key.
+func SignJWT(t testing.TB, key *rsa.PrivateKey, kid string, claims any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatalf("Failed to marshal header: %v", err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Failed to marshal claims: %v", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// NewKey returns an RSA key for signing tokens.
+func NewKey(t testing.TB) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	return key
+}
@@ -0,0 +1,117 @@
+package attest
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"strings"
+)
+
+// jwks is a JSON Web Key Set (RFC 7517, section 5).
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// ReadJWKS reads the RSA keys of the JSON Web Key Set in the named file, by key ID. It returns an error
+// wrapping fs.ErrNotExist if the file doesn't exist.
+func ReadJWKS(fsys fs.FS, name string) (map[string]*rsa.PublicKey, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ParseJWKS(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return keys, nil
+}
+
+// ParseJWKS parses the RSA keys of a JSON Web Key Set, by key ID.
+func ParseJWKS(content []byte) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+
+		n, nErr := base64.RawURLEncoding.DecodeString(key.N)
+		e, eErr := base64.RawURLEncoding.DecodeString(key.E)
+		if err := errors.Join(nErr, eErr); err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no RSA keys found")
+	}
+
+	return keys, nil
+}
+
+// VerifyJWT verifies the RS256 signature of a JSON Web Token with the key named by its header, and returns
+// its decoded payload. The claims are left to the caller to validate.
+func VerifyJWT(token string, keys map[string]*rsa.PublicKey) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("decoding header: %w", err)
+	}
+
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported algorithm %q", header.Alg)
+	}
+
+	key, ok := keys[header.Kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding signature: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	return payload, nil
+}
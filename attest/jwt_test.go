@@ -0,0 +1,74 @@
+package attest
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/attest/attesttest"
+)
+
+func TestReadJWKS(t *testing.T) {
+	key := attesttest.NewKey(t)
+	fsys := fstest.MapFS{
+		"jwks.json":    {Data: attesttest.JWKS(t, map[string]*rsa.PrivateKey{"key-1": key})},
+		"empty.json":   {Data: []byte(`{"keys": [{"kty": "EC", "kid": "key-2"}]}`)},
+		"invalid.json": {Data: []byte(`not json`)},
+	}
+
+	keys, err := ReadJWKS(fsys, "jwks.json")
+	if err != nil {
+		t.Fatalf("ReadJWKS() error = %v", err)
+	}
+	if !keys["key-1"].Equal(&key.PublicKey) {
+		t.Errorf("ReadJWKS() = %v; want key-1", keys)
+	}
+
+	for _, name := range []string{"empty.json", "invalid.json", "missing.json"} {
+		if _, err = ReadJWKS(fsys, name); err == nil {
+			t.Errorf("ReadJWKS(%q) error = nil; want an error", name)
+		}
+	}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	key := attesttest.NewKey(t)
+	otherKey := attesttest.NewKey(t)
+	keys := map[string]*rsa.PublicKey{"key-1": &key.PublicKey}
+	claims := map[string]string{"aud": "clouddetect"}
+
+	token := attesttest.SignJWT(t, key, "key-1", claims)
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr bool
+	}{
+		{name: "Valid token", token: token},
+		{name: "Unknown key ID", token: attesttest.SignJWT(t, key, "key-2", claims), expectedErr: true},
+		{name: "Signed by another key", token: attesttest.SignJWT(t, otherKey, "key-1", claims), expectedErr: true},
+		{name: "Tampered payload", token: parts[0] + "." + parts[0] + "." + parts[2], expectedErr: true},
+		{name: "Unsigned token", token: "eyJhbGciOiJub25lIn0.e30.", expectedErr: true},
+		{name: "Malformed token", token: "not a token", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := VerifyJWT(tt.token, keys)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("VerifyJWT() error = %v; want error %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr {
+				return
+			}
+
+			var got map[string]string
+			if err = json.Unmarshal(payload, &got); err != nil || got["aud"] != "clouddetect" {
+				t.Errorf("VerifyJWT() payload = %s; want the claims", payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package attest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	// Register the hash functions used by PKCS7 signatures.
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+var (
+	oidData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+
+	digestAlgorithms = map[string]crypto.Hash{
+		"1.3.14.3.2.26":          crypto.SHA1,
+		"2.16.840.1.101.3.4.2.1": crypto.SHA256,
+		"2.16.840.1.101.3.4.2.2": crypto.SHA384,
+		"2.16.840.1.101.3.4.2.3": crypto.SHA512,
+	}
+)
+
+// contentInfo is the PKCS #7 ContentInfo structure (RFC 2315, section 7).
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"optional,tag:0"` // Content is explicitly tagged, which RawValue doesn't unwrap.
+}
+
+// signedData is the PKCS #7 SignedData structure (RFC 2315, section 9.1).
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapsulatedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerialNumber     issuerAndSerialNumber
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+// VerifyPKCS7 verifies a DER-encoded PKCS #7 SignedData structure with embedded content, as served by
+// the Azure attested metadata endpoint. The signer's certificate is verified with opts, using the other
+// certificates in the structure as additional intermediates.
+//
+// It returns the signed content and the signer's certificate.
+func VerifyPKCS7(der []byte, opts x509.VerifyOptions) ([]byte, *x509.Certificate, error) {
+	var info contentInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, nil, fmt.Errorf("parsing content info: %w", err)
+	}
+	if !info.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("unexpected content type %s", info.ContentType)
+	}
+
+	var sd signedData
+	if _, err := asn1.Unmarshal(info.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("parsing signed data: %w", err)
+	}
+	if !sd.ContentInfo.ContentType.Equal(oidData) || sd.ContentInfo.Content == nil {
+		return nil, nil, errors.New("no embedded content")
+	}
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("expected a single signer, found %d", len(sd.SignerInfos))
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing certificates: %w", err)
+	}
+
+	si := sd.SignerInfos[0]
+	signer := findSigner(certs, si.IssuerAndSerialNumber)
+	if signer == nil {
+		return nil, nil, errors.New("signer certificate not found")
+	}
+
+	if err = verifySignerInfo(si, sd.ContentInfo.Content, signer); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Intermediates == nil {
+		opts.Intermediates = x509.NewCertPool()
+	}
+	for _, cert := range certs {
+		if cert != signer {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+
+	if _, err = signer.Verify(opts); err != nil {
+		return nil, nil, err
+	}
+
+	return sd.ContentInfo.Content, signer, nil
+}
+
+func findSigner(certs []*x509.Certificate, id issuerAndSerialNumber) *x509.Certificate {
+	for _, cert := range certs {
+		if cert.SerialNumber.Cmp(id.SerialNumber) == 0 && bytes.Equal(cert.RawIssuer, id.Issuer.FullBytes) {
+			return cert
+		}
+	}
+
+	return nil
+}
+
+// verifySignerInfo verifies the signature of the content. When authenticated attributes are present, the
+// signature covers them instead, and they must hold the digest of the content.
+func verifySignerInfo(si signerInfo, content []byte, signer *x509.Certificate) error {
+	hash, ok := digestAlgorithms[si.DigestAlgorithm.Algorithm.String()]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm %s", si.DigestAlgorithm.Algorithm)
+	}
+
+	signed := content
+	if len(si.AuthenticatedAttributes.FullBytes) > 0 {
+		// The attributes are signed as an explicit SET OF, not with their implicit [0] tag.
+		signed = append([]byte{0x31}, si.AuthenticatedAttributes.FullBytes[1:]...)
+
+		digest, err := messageDigest(signed)
+		if err != nil {
+			return err
+		}
+
+		h := hash.New()
+		h.Write(content)
+		if !bytes.Equal(h.Sum(nil), digest) {
+			return errors.New("message digest doesn't match the content")
+		}
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	sum := h.Sum(nil)
+
+	switch key := signer.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, sum, si.EncryptedDigest)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, sum, si.EncryptedDigest) {
+			return errors.New("ecdsa: verification error")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// messageDigest returns the value of the message digest attribute.
+func messageDigest(attributes []byte) ([]byte, error) {
+	var attrs []attribute
+	if _, err := asn1.UnmarshalWithParams(attributes, &attrs, "set"); err != nil {
+		return nil, fmt.Errorf("parsing authenticated attributes: %w", err)
+	}
+
+	for _, attr := range attrs {
+		if !attr.Type.Equal(oidMessageDigest) {
+			continue
+		}
+
+		var digest []byte
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &digest); err != nil {
+			return nil, fmt.Errorf("parsing message digest: %w", err)
+		}
+		return digest, nil
+	}
+
+	return nil, errors.New("no message digest attribute")
+}
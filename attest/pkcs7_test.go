@@ -0,0 +1,88 @@
+package attest
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/attest/attesttest"
+)
+
+func TestVerifyPKCS7(t *testing.T) {
+	ca := attesttest.NewCA(t, "Test Root CA")
+	intermediate := ca.Issue(t)
+	signer := intermediate.Issue(t, "metadata.example.com")
+	otherCA := attesttest.NewCA(t, "Other Root CA")
+
+	content := []byte(`{"nonce": "0123456789"}`)
+
+	roots := func(cas ...*attesttest.Identity) *x509.CertPool {
+		pool := x509.NewCertPool()
+		for _, ca := range cas {
+			pool.AddCert(ca.Cert)
+		}
+		return pool
+	}
+
+	tamper := func(der []byte) []byte {
+		tampered := append([]byte(nil), der...)
+		// Flip a byte of the signature, at the end of the structure.
+		tampered[len(tampered)-1] ^= 0xff
+		return tampered
+	}
+
+	tests := []struct {
+		name        string
+		der         []byte
+		roots       *x509.CertPool
+		expectedErr bool
+	}{
+		{
+			name:  "Valid signature and chain",
+			der:   attesttest.SignPKCS7(t, content, signer, intermediate),
+			roots: roots(ca),
+		},
+		{
+			name:        "Missing intermediate",
+			der:         attesttest.SignPKCS7(t, content, signer),
+			roots:       roots(ca),
+			expectedErr: true,
+		},
+		{
+			name:        "Untrusted root",
+			der:         attesttest.SignPKCS7(t, content, signer, intermediate),
+			roots:       roots(otherCA),
+			expectedErr: true,
+		},
+		{
+			name:        "Tampered signature",
+			der:         tamper(attesttest.SignPKCS7(t, content, signer, intermediate)),
+			roots:       roots(ca),
+			expectedErr: true,
+		},
+		{
+			name:        "Not PKCS7",
+			der:         []byte("not pkcs7"),
+			roots:       roots(ca),
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cert, err := VerifyPKCS7(tt.der, x509.VerifyOptions{Roots: tt.roots})
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("VerifyPKCS7() error = %v; want error %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr {
+				return
+			}
+
+			if string(got) != string(content) {
+				t.Errorf("VerifyPKCS7() content = %q; want %q", got, content)
+			}
+			if !cert.Equal(signer.Cert) {
+				t.Errorf("VerifyPKCS7() signer = %s; want %s", cert.Subject, signer.Cert.Subject)
+			}
+		})
+	}
+}
@@ -4,43 +4,110 @@ package clouddetect
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"slices"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/container"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/kubernetes"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/akamai"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/alibaba"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/aws"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/azure"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/cloudstack"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/digitalocean"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/equinix"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/exoscale"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/gcp"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/hyperv"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/nutanix"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/oci"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/openstack"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/ovh"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/ovirt"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/proxmox"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/upcloud"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/vmware"
 	"github.com/nikhil-prabhu/clouddetect/v2/providers/vultr"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/yandex"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
+	"github.com/nikhil-prabhu/clouddetect/v2/virtualization"
 )
 
 // DefaultDetectionTimeout is the default maximum time allowed for detection.
 const DefaultDetectionTimeout = 5 * time.Second // seconds
 
-// SupportedProviders is a list of supported cloud service providers.
-var SupportedProviders = []types.ProviderId{
+// kubernetesTimeout is the maximum time allowed for the Kubernetes metadata checks, after the provider is detected.
+const kubernetesTimeout = 2 * time.Second
+
+// PublicProviders is a list of supported public cloud service providers.
+var PublicProviders = []types.ProviderId{
 	types.Akamai,
 	types.Alibaba,
 	types.Aws,
 	types.Azure,
+	types.CloudStack,
 	types.DigitalOcean,
+	types.Equinix,
+	types.Exoscale,
 	types.Gcp,
 	types.Oci,
 	types.OpenStack,
+	types.Ovh,
+	types.UpCloud,
 	types.Vultr,
+	types.Yandex,
+}
+
+// PrivateProviders is a list of supported private (on-premises) virtualization platforms.
+var PrivateProviders = []types.ProviderId{
+	types.Hyperv,
+	types.Nutanix,
+	types.Ovirt,
+	types.Proxmox,
+	types.Vmware,
 }
 
+// SupportedProviders is a list of supported providers, public cloud service providers first.
+var SupportedProviders = slices.Concat(PublicProviders, PrivateProviders)
+
 type Option func(*config)
 
+// Result is the outcome of detecting the host's cloud service provider.
+type Result struct {
+	Provider       types.ProviderId         // Provider is the detected cloud service provider, or types.Unknown.
+	Confidence     types.Confidence         // Confidence is how strongly the evidence identifies the provider.
+	Platform       types.Platform           // Platform is the managed runtime (e.g. lambda, cloud-run) the host runs in, if any.
+	Kubernetes     types.KubernetesPlatform // Kubernetes is the Kubernetes distribution (e.g. eks, gke) the host runs in, if any.
+	Container      types.ContainerRuntime   // Container is the container or sandbox runtime (e.g. docker, gvisor) the host runs in, if any.
+	Virtualization types.Virtualization     // Virtualization is the hypervisor (e.g. nitro, kvm) the host runs on, or bare-metal.
+	Metadata       types.Metadata           // Metadata is the normalized instance metadata gathered during detection.
+	Attested       bool                     // Attested reports whether the provider was verified from a signed identity document (see WithAttestation).
+	Checks         []probe.Result           // Checks are the checks that matched the detected provider.
+}
+
+// Detected reports whether the provider was detected with more than low confidence. Low confidence evidence,
+// such as the vendor of a bare-metal server, is only a hint that other hosts may share.
+func (r Result) Detected() bool {
+	return r.Provider != types.Unknown && r.Confidence > types.LowConfidence
+}
+
 type config struct {
-	timeout time.Duration
-	logger  *zap.Logger
+	timeout    time.Duration
+	logger     *zap.Logger
+	root       string
+	include    []types.ProviderId
+	exclude    []types.ProviderId
+	recorder   *probe.Recorder
+	attest     bool
+	trust      fs.FS
+	kubernetes bool
 }
 
 // Provider represents a cloud service provider.
@@ -48,19 +115,38 @@ type config struct {
 // This interface is not guaranteed to remain stable/public and may change or be removed in the future.
 // Do not depend on this interface outside of this package.
 type Provider interface {
-	Identifier() types.ProviderId                                   // Identifier returns the cloud service provider identifier.
-	Identify(context.Context, chan<- types.ProviderId, *zap.Logger) // Identify detects the cloud service provider.
+	Identifier() types.ProviderId                                         // Identifier returns the cloud service provider identifier.
+	Identify(context.Context, chan<- types.Evidence, string, *zap.Logger) // Identify detects the cloud service provider, reading local files under the given root.
+}
+
+// Attester is implemented by providers that can verify the host from a signed identity document.
+//
+// Like Provider, this interface is not guaranteed to remain stable/public.
+type Attester interface {
+	Attest(context.Context, *zap.Logger) bool // Attest reports whether the host's signed identity document is valid.
 }
 
 var providers = map[types.ProviderId]Provider{
+	types.Akamai:       &akamai.Akamai{},
 	types.Alibaba:      &alibaba.Alibaba{},
 	types.Aws:          &aws.Aws{},
 	types.Azure:        &azure.Azure{},
+	types.CloudStack:   &cloudstack.CloudStack{},
 	types.DigitalOcean: &digitalocean.DigitalOcean{},
+	types.Equinix:      &equinix.Equinix{},
+	types.Exoscale:     &exoscale.Exoscale{},
 	types.Gcp:          &gcp.Gcp{},
 	types.Oci:          &oci.Oci{},
 	types.OpenStack:    &openstack.OpenStack{},
+	types.Ovh:          &ovh.Ovh{},
+	types.UpCloud:      &upcloud.UpCloud{},
 	types.Vultr:        &vultr.Vultr{},
+	types.Yandex:       &yandex.Yandex{},
+	types.Hyperv:       &hyperv.Hyperv{},
+	types.Nutanix:      &nutanix.Nutanix{},
+	types.Ovirt:        &ovirt.Ovirt{},
+	types.Proxmox:      &proxmox.Proxmox{},
+	types.Vmware:       &vmware.Vmware{},
 }
 
 func WithTimeout(timeout time.Duration) Option {
@@ -75,45 +161,242 @@ func WithLogger(logger *zap.Logger) Option {
 	}
 }
 
+// WithRoot sets the directory local files (e.g. /sys/class/dmi/id/sys_vendor) are read relative to.
+// It defaults to "/", and can point at a mounted copy of another host's filesystem or at test fixtures.
+func WithRoot(root string) Option {
+	return func(c *config) {
+		c.root = root
+	}
+}
+
+// WithProviders restricts detection to the given providers.
+func WithProviders(ids ...types.ProviderId) Option {
+	return func(c *config) {
+		c.include = append(c.include, ids...)
+	}
+}
+
+// WithoutProviders excludes the given providers from detection.
+func WithoutProviders(ids ...types.ProviderId) Option {
+	return func(c *config) {
+		c.exclude = append(c.exclude, ids...)
+	}
+}
+
+// WithRecorder records the result of every check the providers run in recorder.
+//
+// Detection then waits for every provider to finish (or for the timeout) rather than returning as soon as
+// a provider is detected, so that the checks of the other providers are recorded too.
+func WithRecorder(recorder *probe.Recorder) Option {
+	return func(c *config) {
+		c.recorder = recorder
+	}
+}
+
+// WithAttestation verifies the detected provider from the signed identity document served by its metadata
+// service, if it supports one, and reports the outcome as Result.Attested. Evidence served by anyone else
+// answering on the metadata address (e.g. a container sharing the host's network) isn't attested.
+//
+// Documents are verified with the trust material in trust (see package attest for its layout), or with the
+// embedded trust material if trust is nil.
+func WithAttestation(trust fs.FS) Option {
+	return func(c *config) {
+		c.attest = true
+		c.trust = trust
+	}
+}
+
+// WithKubernetes identifies the managed Kubernetes distribution (e.g. eks, gke) the host runs in from the
+// metadata of the detected provider, and reports it as Result.Kubernetes. This takes further metadata requests,
+// for up to 2 seconds, so without it pods are only reported as types.Kubernetes.
+func WithKubernetes() Option {
+	return func(c *config) {
+		c.kubernetes = true
+	}
+}
+
 // Detect detects the host's cloud service provider.
 // Options can be passed to customize the detection behavior, such as setting a custom timeout and logger.
+//
+// Providers only reported with low confidence aren't detected (see Result.Detected), and types.Unknown is
+// returned instead. Use DetectResult to get them.
 func Detect(opts ...Option) types.ProviderId {
+	result := DetectResult(opts...)
+	if !result.Detected() {
+		return types.Unknown
+	}
+
+	return result.Provider
+}
+
+// DetectResult detects the host's cloud service provider and returns it along with
+// the confidence of the detection and any metadata gathered along the way.
+//
+// Evidence of medium or high confidence is returned as soon as it is reported. Low confidence
+// evidence is only returned if no provider reports anything stronger before detection completes.
+func DetectResult(opts ...Option) Result {
 	// Default config
 	cfg := config{
 		timeout: DefaultDetectionTimeout,
 		logger:  zap.NewNop(),
+		root:    "/",
 	}
 
 	for _, o := range opts {
 		o(&cfg)
 	}
 
-	ch := make(chan types.ProviderId, 1)
+	ch := make(chan types.Evidence, len(providers))
 	wg := sync.WaitGroup{}
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.timeout)
 	defer cancel()
 
+	ctx = detection.WithRun(ctx, detection.NewRun(cfg.enabled))
+
+	// The checks are always recorded, so that the ones that matched can be reported as Result.Checks.
+	recorder := cfg.recorder
+	if recorder == nil {
+		recorder = &probe.Recorder{}
+	}
+	ctx = probe.WithRecorder(ctx, recorder)
+
 	for name, provider := range providers {
+		if !cfg.enabled(name) {
+			cfg.logger.Debug(fmt.Sprintf("Skipping detection routine for %s", name))
+			continue
+		}
+
 		wg.Add(1)
 		go func(name types.ProviderId, provider Provider) {
 			cfg.logger.Debug(fmt.Sprintf("Starting detection routine for %s", name))
 			defer wg.Done()
-			provider.Identify(ctx, ch, cfg.logger)
+			provider.Identify(ctx, ch, cfg.root, cfg.logger)
 		}(name, provider)
 	}
 
+	done := make(chan struct{})
 	go func() {
 		wg.Wait()
-		cancel()
+		close(done)
 	}()
 
-	select {
-	case result := <-ch:
-		cfg.logger.Info(fmt.Sprintf("Detected cloud service provider: %s", result))
-		return result
-	case <-ctx.Done():
-		cfg.logger.Error(fmt.Sprintf("Detection timed out after %d seconds", cfg.timeout))
-		return types.Unknown
+	result := cfg.result(cfg.collect(ctx, ch, done))
+	result.Checks = matchedChecks(recorder.Results(), result.Provider)
+	if cfg.recorder != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
+	if cfg.attest {
+		result.Attested = cfg.attestation(ctx, result.Provider)
+	}
+
+	if cfg.kubernetes {
+		// The detection may have used up its timeout waiting for the providers, so the Kubernetes metadata
+		// checks get their own.
+		kubernetesCtx, kubernetesCancel := context.WithTimeout(context.Background(), min(cfg.timeout, kubernetesTimeout))
+		defer kubernetesCancel()
+
+		result.Kubernetes = kubernetes.Detect(kubernetesCtx, result.Provider, cfg.root, cfg.logger)
+	} else if kubernetes.InPod(cfg.root, cfg.logger) {
+		result.Kubernetes = types.Kubernetes
+	}
+	result.Container = container.Detect(cfg.root, cfg.logger)
+	result.Virtualization = virtualization.Detect(cfg.root, cfg.logger)
+
+	return result
+}
+
+// enabled reports whether the provider is selected by the include and exclude lists.
+func (c *config) enabled(id types.ProviderId) bool {
+	if len(c.include) > 0 && !slices.Contains(c.include, id) {
+		return false
+	}
+
+	return !slices.Contains(c.exclude, id)
+}
+
+// collect returns the strongest evidence reported by the providers. Evidence above low confidence is
+// returned as soon as it is received; otherwise collect waits for every provider to finish or for the timeout.
+func (c *config) collect(ctx context.Context, ch <-chan types.Evidence, done <-chan struct{}) *types.Evidence {
+	var best *types.Evidence
+	consider := func(evidence types.Evidence) bool {
+		c.logger.Debug(fmt.Sprintf("Received %s confidence evidence for %s", evidence.Confidence, evidence.Provider))
+		if best == nil || evidence.Confidence > best.Confidence {
+			best = &evidence
+		}
+
+		return evidence.Confidence > types.LowConfidence
+	}
+
+	for {
+		select {
+		case evidence := <-ch:
+			if consider(evidence) {
+				return best
+			}
+		case <-done:
+			// Every provider has finished, but some may have reported evidence we haven't received yet.
+			for len(ch) > 0 {
+				if consider(<-ch) {
+					break
+				}
+			}
+			return best
+		case <-ctx.Done():
+			if best == nil {
+				c.logger.Error(fmt.Sprintf("Detection timed out after %d seconds", c.timeout))
+			}
+			return best
+		}
+	}
+}
+
+// matchedChecks returns the checks of the provider that matched the host. A provider reports its evidence after
+// the checks it is based on have been recorded, so they are all there once the provider is detected.
+func matchedChecks(results []probe.Result, id types.ProviderId) []probe.Result {
+	var checks []probe.Result
+	for _, r := range results {
+		if r.Provider == id && r.Outcome == probe.Pass {
+			checks = append(checks, r)
+		}
+	}
+
+	return checks
+}
+
+// attestation verifies the provider's signed identity document, if it supports one.
+func (c *config) attestation(ctx context.Context, id types.ProviderId) bool {
+	attester, ok := providers[id].(Attester)
+	if !ok {
+		c.logger.Debug(fmt.Sprintf("Skipping attestation for %s", id))
+		return false
+	}
+
+	if c.trust != nil {
+		ctx = attest.WithTrust(ctx, c.trust)
+	}
+
+	attested := attester.Attest(ctx, c.logger)
+	c.logger.Info(fmt.Sprintf("Attestation for %s: %t", id, attested))
+
+	return attested
+}
+
+func (c *config) result(evidence *types.Evidence) Result {
+	if evidence == nil {
+		return Result{Provider: types.Unknown}
+	}
+
+	c.logger.Info(fmt.Sprintf("Detected cloud service provider: %s (%s confidence)", evidence.Provider, evidence.Confidence))
+
+	return Result{
+		Provider:   evidence.Provider,
+		Confidence: evidence.Confidence,
+		Platform:   evidence.Platform,
+		Metadata:   evidence.Metadata,
 	}
 }
@@ -1,14 +1,61 @@
 package clouddetect
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
 	"slices"
 	"testing"
+	"testing/fstest"
 	"time"
 
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
+type fakeProvider struct {
+	evidence types.Evidence
+	delay    time.Duration
+}
+
+func (f *fakeProvider) Identifier() types.ProviderId {
+	return f.evidence.Provider
+}
+
+func (f *fakeProvider) Identify(ctx context.Context, ch chan<- types.Evidence, _ string, _ *zap.Logger) {
+	select {
+	case <-time.After(f.delay):
+		probe.Run(ctx, f.evidence.Provider, "fake", "", func() bool { return true })
+		ch <- f.evidence
+	case <-ctx.Done():
+	}
+}
+
+// fakeAttester is a fakeProvider that is attested if the trust material contains its attestation file.
+type fakeAttester struct {
+	fakeProvider
+}
+
+func (f *fakeAttester) Attest(ctx context.Context, _ *zap.Logger) bool {
+	_, err := fs.Stat(attest.Trust(ctx), string(f.evidence.Provider)+"/attested")
+	return err == nil
+}
+
+func withProviders(t *testing.T, fakes map[types.ProviderId]Provider) {
+	original := providers
+	providers = fakes
+	t.Cleanup(func() {
+		providers = original
+	})
+}
+
 func ExampleDetect_default() {
 	// Detect the cloud service provider with default timeout.
 	_ = Detect()
@@ -46,7 +93,15 @@ func ExampleSupportedProviders() {
 	fmt.Println("Supported cloud service providers:", SupportedProviders)
 
 	// Output:
-	// Supported cloud service providers: [akamai alibaba aws azure digitalocean gcp oci openstack vultr]
+	// Supported cloud service providers: [akamai alibaba aws azure cloudstack digitalocean equinix exoscale gcp oci openstack ovh upcloud vultr yandex hyperv nutanix ovirt proxmox vmware]
+}
+
+func ExamplePrivateProviders() {
+	// Print the currently supported private virtualization platforms.
+	fmt.Println("Supported private platforms:", PrivateProviders)
+
+	// Output:
+	// Supported private platforms: [hyperv nutanix ovirt proxmox vmware]
 }
 
 func TestDetect(t *testing.T) {
@@ -56,3 +111,230 @@ func TestDetect(t *testing.T) {
 		t.Errorf("Expected provider to be one of %v, got %s", SupportedProviders, string(provider))
 	}
 }
+
+func TestSupportedProvidersRegistered(t *testing.T) {
+	for _, id := range SupportedProviders {
+		if _, ok := providers[id]; !ok {
+			t.Errorf("providers has no detection routine for %s", id)
+		}
+	}
+
+	if len(providers) != len(SupportedProviders) {
+		t.Errorf("providers has %d detection routines; want %d", len(providers), len(SupportedProviders))
+	}
+}
+
+func TestDetectResultPrefersStrongerEvidence(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Equinix: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Equinix, Confidence: types.LowConfidence},
+		},
+		types.Aws: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Aws, Confidence: types.HighConfidence},
+			delay:    50 * time.Millisecond,
+		},
+	})
+
+	result := DetectResult(WithTimeout(time.Second))
+	if result.Provider != types.Aws || result.Confidence != types.HighConfidence {
+		t.Errorf("DetectResult() = %+v; want high confidence %s", result, types.Aws)
+	}
+}
+
+func TestDetectResultFallsBackToLowConfidence(t *testing.T) {
+	metadata := types.Metadata{InstanceID: "abc", Region: "da", Zone: "da11"}
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Equinix: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Equinix, Confidence: types.LowConfidence, Metadata: metadata},
+		},
+	})
+
+	result := DetectResult(WithTimeout(time.Second))
+	if result.Provider != types.Equinix || result.Confidence != types.LowConfidence {
+		t.Errorf("DetectResult() = %+v; want low confidence %s", result, types.Equinix)
+	}
+	if result.Metadata != metadata {
+		t.Errorf("DetectResult() metadata = %+v; want %+v", result.Metadata, metadata)
+	}
+}
+
+func TestDetectIgnoresLowConfidence(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Equinix: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Equinix, Confidence: types.LowConfidence},
+		},
+	})
+
+	if provider := Detect(WithTimeout(time.Second)); provider != types.Unknown {
+		t.Errorf("Detect() = %s; want %s for low confidence evidence", provider, types.Unknown)
+	}
+}
+
+func TestResultDetected(t *testing.T) {
+	tests := []struct {
+		result   Result
+		expected bool
+	}{
+		{result: Result{Provider: types.Aws, Confidence: types.HighConfidence}, expected: true},
+		{result: Result{Provider: types.Vmware, Confidence: types.MediumConfidence}, expected: true},
+		{result: Result{Provider: types.Equinix, Confidence: types.LowConfidence}, expected: false},
+		{result: Result{Provider: types.Unknown}, expected: false},
+	}
+
+	for _, tt := range tests {
+		if detected := tt.result.Detected(); detected != tt.expected {
+			t.Errorf("%+v.Detected() = %v; want %v", tt.result, detected, tt.expected)
+		}
+	}
+}
+
+func TestDetectResultWithProviders(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Aws: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Aws, Confidence: types.HighConfidence},
+		},
+		types.Gcp: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Gcp, Confidence: types.MediumConfidence},
+			delay:    50 * time.Millisecond,
+		},
+	})
+
+	if result := DetectResult(WithTimeout(time.Second), WithProviders(types.Gcp)); result.Provider != types.Gcp {
+		t.Errorf("DetectResult(WithProviders(%s)) = %+v; want %s", types.Gcp, result, types.Gcp)
+	}
+
+	if result := DetectResult(WithTimeout(time.Second), WithoutProviders(types.Aws)); result.Provider != types.Gcp {
+		t.Errorf("DetectResult(WithoutProviders(%s)) = %+v; want %s", types.Aws, result, types.Gcp)
+	}
+
+	if result := DetectResult(WithTimeout(time.Second), WithProviders(types.Aws), WithoutProviders(types.Aws)); result.Provider != types.Unknown {
+		t.Errorf("DetectResult() with every provider excluded = %+v; want %s", result, types.Unknown)
+	}
+}
+
+func TestDetectResultWithRecorder(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Aws: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Aws, Confidence: types.HighConfidence},
+		},
+		types.Gcp: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Gcp, Confidence: types.MediumConfidence},
+			delay:    50 * time.Millisecond,
+		},
+	})
+
+	recorder := &probe.Recorder{}
+	if result := DetectResult(WithTimeout(time.Second), WithRecorder(recorder)); result.Provider != types.Aws {
+		t.Errorf("DetectResult(WithRecorder()) = %+v; want %s", result, types.Aws)
+	}
+
+	// The checks of providers still running when AWS was detected are recorded too.
+	var recorded []types.ProviderId
+	for _, result := range recorder.Results() {
+		recorded = append(recorded, result.Provider)
+	}
+	slices.Sort(recorded)
+
+	if want := []types.ProviderId{types.Aws, types.Gcp}; !slices.Equal(recorded, want) {
+		t.Errorf("Recorder.Results() providers = %v; want %v", recorded, want)
+	}
+}
+
+func TestDetectResultChecks(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Aws: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Aws, Confidence: types.HighConfidence},
+		},
+		types.Gcp: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Gcp, Confidence: types.MediumConfidence},
+			delay:    50 * time.Millisecond,
+		},
+	})
+
+	// The matched checks are reported without a recorder, and without waiting for the other providers.
+	start := time.Now()
+	result := DetectResult(WithTimeout(time.Second))
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("DetectResult() took %s; want it to return as soon as %s is detected", elapsed, types.Aws)
+	}
+
+	if len(result.Checks) != 1 || result.Checks[0].Provider != types.Aws || result.Checks[0].Check != "fake" {
+		t.Errorf("DetectResult() checks = %+v; want the matched %s check", result.Checks, types.Aws)
+	}
+}
+
+func TestDetectResultWithAttestation(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Aws: &fakeAttester{fakeProvider{
+			evidence: types.Evidence{Provider: types.Aws, Confidence: types.HighConfidence},
+		}},
+		types.Vmware: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Vmware, Confidence: types.MediumConfidence},
+		},
+	})
+
+	trust := fstest.MapFS{"aws/attested": {}}
+
+	tests := []struct {
+		name             string
+		opts             []Option
+		expectedAttested bool
+	}{
+		{name: "Attestation disabled", opts: []Option{WithProviders(types.Aws)}, expectedAttested: false},
+		{name: "Attested provider", opts: []Option{WithProviders(types.Aws), WithAttestation(trust)}, expectedAttested: true},
+		{name: "Missing trust material", opts: []Option{WithProviders(types.Aws), WithAttestation(fstest.MapFS{})}, expectedAttested: false},
+		{name: "Provider without attestation", opts: []Option{WithProviders(types.Vmware), WithAttestation(trust)}, expectedAttested: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DetectResult(append(tt.opts, WithTimeout(time.Second))...)
+			if result.Attested != tt.expectedAttested {
+				t.Errorf("DetectResult() attested = %v; want %v", result.Attested, tt.expectedAttested)
+			}
+		})
+	}
+}
+
+func TestDetectResultWithKubernetes(t *testing.T) {
+	withProviders(t, map[types.ProviderId]Provider{
+		types.Gcp: &fakeProvider{
+			evidence: types.Evidence{Provider: types.Gcp, Confidence: types.HighConfidence},
+		},
+	})
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder(http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/attributes/cluster-name",
+		httpmock.NewStringResponder(200, "prod-cluster"))
+
+	root := testutil.CreateRoot(t, map[string]string{"/var/run/secrets/kubernetes.io/serviceaccount/token": "token"})
+
+	tests := []struct {
+		name               string
+		opts               []Option
+		expectedKubernetes types.KubernetesPlatform
+		expectedRequests   int
+	}{
+		{name: "Pod without WithKubernetes", opts: []Option{WithRoot(root)}, expectedKubernetes: types.Kubernetes},
+		{name: "Pod with WithKubernetes", opts: []Option{WithRoot(root), WithKubernetes()}, expectedKubernetes: types.Gke, expectedRequests: 1},
+		{name: "Not a pod", opts: []Option{WithRoot(t.TempDir()), WithKubernetes()}, expectedKubernetes: types.NoKubernetes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the token file under the root tells whether the host is a pod.
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			_ = os.Unsetenv("KUBERNETES_SERVICE_HOST")
+			httpmock.ZeroCallCounters()
+
+			result := DetectResult(append(tt.opts, WithTimeout(time.Second))...)
+			if result.Kubernetes != tt.expectedKubernetes {
+				t.Errorf("DetectResult() kubernetes = %v; want %v", result.Kubernetes, tt.expectedKubernetes)
+			}
+			if n := httpmock.GetTotalCallCount(); n != tt.expectedRequests {
+				t.Errorf("DetectResult() sent %d metadata requests; want %d", n, tt.expectedRequests)
+			}
+		})
+	}
+}
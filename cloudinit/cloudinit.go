@@ -0,0 +1,146 @@
+// Package cloudinit reads the datasource cloud-init detected on the host, for the checks of the providers
+// it supports.
+//
+// cloud-init writes its results under /run, which is cleared on boot, so they always describe the running host.
+package cloudinit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	// InstanceDataFile is the instance data cloud-init gathered from the datasource, without sensitive keys.
+	InstanceDataFile = "/run/cloud-init/instance-data.json"
+	// DSIdentifyLogFile is the log of ds-identify, which selects the datasources cloud-init runs from local hints.
+	DSIdentifyLogFile = "/run/cloud-init/ds-identify.log"
+)
+
+// names are the cloud names cloud-init reports (v1.cloud_name), and the datasource names ds-identify reports,
+// lowercased. They are the same except for AWS, whose datasource is Ec2.
+var names = map[string]types.ProviderId{
+	"akamai":       types.Akamai,
+	"aliyun":       types.Alibaba,
+	"aws":          types.Aws,
+	"azure":        types.Azure,
+	"cloudstack":   types.CloudStack,
+	"digitalocean": types.DigitalOcean,
+	"ec2":          types.Aws,
+	"exoscale":     types.Exoscale,
+	"gce":          types.Gcp,
+	"openstack":    types.OpenStack,
+	"oracle":       types.Oci,
+	"upcloud":      types.UpCloud,
+	"vmware":       types.Vmware,
+	"vultr":        types.Vultr,
+}
+
+// dsIdentifyPattern matches the datasources ds-identify selected, e.g. "Found single datasource: Ec2" or
+// "datasource_list: [ Azure, None ]".
+var dsIdentifyPattern = regexp.MustCompile(`(?:Found single datasource:|datasource_list:)\s*\[?\s*([A-Za-z0-9]+)`)
+
+// instanceData is the standardized (v1) part of the instance data.
+type instanceData struct {
+	V1 struct {
+		CloudName        string `json:"cloud_name"`
+		Platform         string `json:"platform"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availability_zone"`
+		InstanceID       string `json:"instance_id"`
+	} `json:"v1"`
+}
+
+// Provider returns the provider of a cloud or datasource name reported by cloud-init, or types.Unknown.
+func Provider(name string) types.ProviderId {
+	if id, ok := names[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return id
+	}
+
+	return types.Unknown
+}
+
+// MatchInstanceData reports whether the cloud name in the instance data file under root is the provider's, and
+// returns the metadata read from it. The check is recorded as one of the provider's.
+func MatchInstanceData(ctx context.Context, id types.ProviderId, root string, logger *zap.Logger) (types.Metadata, bool) {
+	var metadata types.Metadata
+	file := filepath.Join(root, InstanceDataFile)
+	ok := probe.File(ctx, id, "cloud-init instance data", file, func() (ok bool) {
+		metadata, ok = checkInstanceDataFile(file, id, logger)
+		return ok
+	})
+
+	return metadata, ok
+}
+
+// MatchDSIdentify reports whether the datasource in the ds-identify log under root is the provider's. The check is
+// recorded as one of the provider's.
+func MatchDSIdentify(ctx context.Context, id types.ProviderId, root string, logger *zap.Logger) bool {
+	file := filepath.Join(root, DSIdentifyLogFile)
+	return probe.File(ctx, id, "cloud-init ds-identify log", file, func() bool { return checkDSIdentifyLogFile(file, id, logger) })
+}
+
+// checkInstanceDataFile reports whether the cloud name in the instance data file is the provider's, and returns
+// the metadata read from it.
+func checkInstanceDataFile(file string, id types.ProviderId, logger *zap.Logger) (types.Metadata, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s cloud-init instance data file %s", id, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return types.Metadata{}, false
+	}
+
+	data := new(instanceData)
+	if err = json.Unmarshal(content, data); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding file: %s", err))
+		return types.Metadata{}, false
+	}
+
+	logger.Debug(fmt.Sprintf("cloud-init cloud name: %q, platform: %q", data.V1.CloudName, data.V1.Platform))
+
+	if !matches(data.V1.CloudName, id) {
+		return types.Metadata{}, false
+	}
+
+	return types.Metadata{
+		InstanceID: data.V1.InstanceID,
+		Region:     data.V1.Region,
+		Zone:       data.V1.AvailabilityZone,
+	}, true
+}
+
+// checkDSIdentifyLogFile reports whether the datasource ds-identify selected is the provider's. It is selected
+// from local hints (mostly DMI) before the datasource is queried, so it isn't as strong as the instance data.
+func checkDSIdentifyLogFile(file string, id types.ProviderId, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s cloud-init ds-identify log file %s", id, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	// ds-identify may run more than once per boot; the last run wins.
+	found := dsIdentifyPattern.FindAllStringSubmatch(string(content), -1)
+	if len(found) == 0 {
+		return false
+	}
+
+	return matches(found[len(found)-1][1], id)
+}
+
+// matches reports whether the cloud or datasource name is the provider's. Unrecognized names match no provider.
+func matches(name string, id types.ProviderId) bool {
+	provider := Provider(name)
+	return provider != types.Unknown && provider == id
+}
@@ -0,0 +1,192 @@
+package cloudinit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	return file
+}
+
+func TestProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected types.ProviderId
+	}{
+		{name: "aws", expected: types.Aws},
+		{name: "Ec2", expected: types.Aws},
+		{name: "gce", expected: types.Gcp},
+		{name: "Azure", expected: types.Azure},
+		{name: "oracle", expected: types.Oci},
+		{name: "aliyun", expected: types.Alibaba},
+		{name: "nocloud", expected: types.Unknown},
+		{name: "", expected: types.Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := Provider(tt.name); got != tt.expected {
+			t.Errorf("Provider(%q) = %v; want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckInstanceDataFile(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileContent      string
+		id               types.ProviderId
+		expectedMetadata types.Metadata
+		expectedResult   bool
+	}{
+		{
+			name: "Matching cloud name",
+			fileContent: `{"v1": {"cloud_name": "aws", "platform": "ec2", "region": "eu-west-1",
+				"availability_zone": "eu-west-1a", "instance_id": "i-0123456789abcdef0"}}`,
+			id:               types.Aws,
+			expectedMetadata: types.Metadata{InstanceID: "i-0123456789abcdef0", Region: "eu-west-1", Zone: "eu-west-1a"},
+			expectedResult:   true,
+		},
+		{
+			name:           "Matching cloud name without metadata",
+			fileContent:    `{"v1": {"cloud_name": "gce"}}`,
+			id:             types.Gcp,
+			expectedResult: true,
+		},
+		{
+			name:           "Other cloud name",
+			fileContent:    `{"v1": {"cloud_name": "azure", "instance_id": "vm"}}`,
+			id:             types.Aws,
+			expectedResult: false,
+		},
+		{
+			name:           "Unknown cloud name",
+			fileContent:    `{"v1": {"cloud_name": "nocloud"}}`,
+			id:             types.Unknown,
+			expectedResult: false,
+		},
+		{
+			name:           "Invalid JSON",
+			fileContent:    `{"v1":`,
+			id:             types.Aws,
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := createTempFile(t, tt.fileContent)
+
+			metadata, ok := checkInstanceDataFile(file, tt.id, zap.NewNop())
+			if ok != tt.expectedResult || metadata != tt.expectedMetadata {
+				t.Errorf("checkInstanceDataFile() = %+v, %v; want %+v, %v", metadata, ok, tt.expectedMetadata, tt.expectedResult)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		if _, ok := checkInstanceDataFile(filepath.Join(t.TempDir(), "missing"), types.Aws, zap.NewNop()); ok {
+			t.Error("checkInstanceDataFile() = true; want false")
+		}
+	})
+}
+
+func TestCheckDSIdentifyLogFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		id             types.ProviderId
+		expectedResult bool
+	}{
+		{
+			name:           "Single datasource",
+			fileContent:    "[up 1.23s] ds-identify\nFound single datasource: Ec2\n",
+			id:             types.Aws,
+			expectedResult: true,
+		},
+		{
+			name:           "Datasource list",
+			fileContent:    "[up 1.23s] ds-identify\nfound=all maybe=\ndatasource_list: [ Azure, None ]\n",
+			id:             types.Azure,
+			expectedResult: true,
+		},
+		{
+			name:           "Last run wins",
+			fileContent:    "Found single datasource: Ec2\n[up 9.87s] ds-identify\nFound single datasource: Oracle\n",
+			id:             types.Aws,
+			expectedResult: false,
+		},
+		{
+			name:           "Other datasource",
+			fileContent:    "Found single datasource: NoCloud\n",
+			id:             types.Aws,
+			expectedResult: false,
+		},
+		{
+			name:           "No datasource",
+			fileContent:    "[up 1.23s] ds-identify\nNo ds found [mode=search, notfound=disabled]\n",
+			id:             types.Aws,
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := createTempFile(t, tt.fileContent)
+
+			if ok := checkDSIdentifyLogFile(file, tt.id, zap.NewNop()); ok != tt.expectedResult {
+				t.Errorf("checkDSIdentifyLogFile() = %v; want %v", ok, tt.expectedResult)
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		if checkDSIdentifyLogFile(filepath.Join(t.TempDir(), "missing"), types.Aws, zap.NewNop()) {
+			t.Error("checkDSIdentifyLogFile() = true; want false")
+		}
+	})
+}
+
+func TestMatchInstanceData(t *testing.T) {
+	root := testutil.CreateRoot(t, map[string]string{InstanceDataFile: `{"v1": {"cloud_name": "aws", "region": "eu-west-1"}}`})
+
+	recorder := new(probe.Recorder)
+	ctx := probe.WithRecorder(context.Background(), recorder)
+
+	if metadata, ok := MatchInstanceData(ctx, types.Aws, root, zap.NewNop()); !ok || metadata.Region != "eu-west-1" {
+		t.Errorf("MatchInstanceData(aws) = %+v, %v; want region eu-west-1, true", metadata, ok)
+	}
+	if _, ok := MatchInstanceData(ctx, types.Gcp, root, zap.NewNop()); ok {
+		t.Error("MatchInstanceData(gcp) = true; want false")
+	}
+
+	results := recorder.Results()
+	if len(results) != 2 || results[0].Provider != types.Aws || results[0].Outcome != probe.Pass ||
+		results[1].Provider != types.Gcp || results[1].Outcome != probe.Fail {
+		t.Errorf("recorded %+v; want a passing aws check and a failing gcp check", results)
+	}
+}
+
+func TestMatchDSIdentify(t *testing.T) {
+	root := testutil.CreateRoot(t, map[string]string{DSIdentifyLogFile: "Found single datasource: Ec2\n"})
+
+	if !MatchDSIdentify(context.Background(), types.Aws, root, zap.NewNop()) {
+		t.Error("MatchDSIdentify(aws) = false; want true")
+	}
+	if MatchDSIdentify(context.Background(), types.Azure, root, zap.NewNop()) {
+		t.Error("MatchDSIdentify(azure) = true; want false")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+)
+
+// explain runs the detection and prints every check the providers ran, followed by the result.
+func explain(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("clouddetect explain", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: clouddetect explain [flags]\n\nPrints every check run to detect the cloud service provider of the host, and its outcome.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	detection := addDetectFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if flags.NArg() > 0 {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: unexpected argument %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitError
+	}
+
+	opts, logger, ok := detection.options(stderr)
+	if !ok {
+		return exitError
+	}
+	defer syncLogger(logger)
+
+	recorder := &probe.Recorder{}
+	result := detect(append(opts, clouddetect.WithRecorder(recorder))...)
+
+	if err := writeExplanation(stdout, recorder.Results(), result); err != nil {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: %s\n", err)
+		return exitError
+	}
+
+	return exitCode(result)
+}
+
+// writeExplanation writes a table of the checks, grouped by provider in the order they ran, and the result.
+func writeExplanation(w io.Writer, results []probe.Result, result clouddetect.Result) error {
+	results = slices.Clone(results)
+	slices.SortStableFunc(results, func(a, b probe.Result) int {
+		return strings.Compare(string(a.Provider), string(b.Provider))
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "PROVIDER\tCHECK\tSOURCE\tDURATION\tVALUE\tRESULT")
+	for _, r := range results {
+		outcome := string(r.Outcome)
+		if r.Err != "" {
+			outcome += ": " + r.Err
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Provider, r.Check, r.Source, r.Duration.Round(time.Microsecond), orDash(r.Value), outcome)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nResult: %s (%s confidence)\n", result.Provider, result.Confidence)
+	return err
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// withRoot runs the real detection against a root containing the given sys_vendor file.
+func withRoot(t *testing.T, vendor string) {
+	root := testutil.CreateRoot(t, map[string]string{"/sys/class/dmi/id/sys_vendor": vendor + "\n"})
+
+	original := detect
+	detect = func(opts ...clouddetect.Option) clouddetect.Result {
+		return clouddetect.DetectResult(append(opts, clouddetect.WithRoot(root))...)
+	}
+	t.Cleanup(func() {
+		detect = original
+	})
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name            string
+		vendor          string
+		expectedCode    int
+		expectedOutcome string
+		expectedChecks  int
+		expectedResult  string
+	}{
+		{
+			name:            "Detected provider",
+			vendor:          "VMware, Inc.",
+			expectedCode:    exitOK,
+			expectedOutcome: "pass",
+			expectedChecks:  2, // The cloud-init instance data check runs first.
+			expectedResult:  "Result: vmware (medium confidence)",
+		},
+		{
+			name:            "Unknown provider",
+			vendor:          "QEMU",
+			expectedCode:    exitUnknown,
+			expectedOutcome: "fail",
+			expectedChecks:  4, // The cloud-init and MAC address checks run around the sys_vendor check.
+			expectedResult:  "Result: unknown (none confidence)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRoot(t, tt.vendor)
+
+			var stdout, stderr bytes.Buffer
+			code := run([]string{"explain", "-include", "vmware", "-timeout", "1s"}, &stdout, &stderr)
+
+			if code != tt.expectedCode {
+				t.Errorf("run() = %d; want %d (stderr: %s)", code, tt.expectedCode, stderr.String())
+			}
+
+			lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			if len(lines) != tt.expectedChecks+3 {
+				t.Fatalf("run() output = %q; want a header, %d checks and the result", stdout.String(), tt.expectedChecks)
+			}
+
+			if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "PROVIDER CHECK SOURCE DURATION VALUE RESULT" {
+				t.Errorf("run() header = %q", lines[0])
+			}
+			var check string
+			for _, line := range lines[1 : len(lines)-1] {
+				if strings.Contains(line, "sys_vendor file") {
+					check = line
+				}
+			}
+			if !strings.HasPrefix(check, "vmware") || !strings.Contains(check, tt.vendor) || !strings.HasSuffix(check, tt.expectedOutcome) {
+				t.Errorf("run() check = %q; want the vmware sys_vendor check with outcome %s", check, tt.expectedOutcome)
+			}
+			if result := lines[len(lines)-1]; result != tt.expectedResult {
+				t.Errorf("run() result = %q; want %q", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestExplainUsage(t *testing.T) {
+	withResult(t, clouddetect.Result{Provider: types.Aws})
+
+	for _, args := range [][]string{{"-include", "heroku"}, {"aws"}, {"-output", "json"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(append([]string{"explain"}, args...), &stdout, &stderr); code != exitError {
+			t.Errorf("run(explain %v) = %d; want %d", args, code, exitError)
+		}
+	}
+}
+
+func TestWriteExplanation(t *testing.T) {
+	results := []probe.Result{
+		{Provider: types.Gcp, Check: "metadata server", Source: "http://metadata.google.internal/", Duration: time.Millisecond, Outcome: probe.Error, Err: "connection refused"},
+		{Provider: types.Aws, Check: "imdsv2", Source: "http://169.254.169.254/", Duration: 2 * time.Millisecond, Value: "200 ok", Outcome: probe.Pass},
+	}
+
+	var out bytes.Buffer
+	if err := writeExplanation(&out, results, clouddetect.Result{Provider: types.Aws, Confidence: types.HighConfidence}); err != nil {
+		t.Fatalf("writeExplanation() error = %v", err)
+	}
+
+	expected := `PROVIDER  CHECK            SOURCE                            DURATION  VALUE   RESULT
+aws       imdsv2           http://169.254.169.254/           2ms       200 ok  pass
+gcp       metadata server  http://metadata.google.internal/  1ms       -       error: connection refused
+
+Result: aws (high confidence)
+`
+	if out.String() != expected {
+		t.Errorf("writeExplanation() = %q; want %q", out.String(), expected)
+	}
+}
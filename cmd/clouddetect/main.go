@@ -0,0 +1,224 @@
+// Command clouddetect prints the cloud service provider of the host.
+//
+// With -output json, yaml, env or facts it prints the full detection result instead, following
+// a versioned schema (see the schema_version field).
+//
+// Usage:
+//
+//	clouddetect [flags]
+//	clouddetect explain [flags]
+//	clouddetect serve [flags]
+//
+// The explain subcommand prints every check each provider ran, with the source it read, how long it took,
+// the value it found and its outcome, followed by the result.
+//
+// The serve subcommand detects the provider once and serves the result over HTTP, on a loopback address
+// or a Unix socket, so that other processes on the host don't have to repeat the detection.
+//
+// The exit status is 0 if a provider was detected, 1 if the provider is unknown and 2 on error,
+// so scripts can run e.g. `if clouddetect -q; then ...`.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	exitOK      = 0 // exitOK is returned when a provider was detected.
+	exitUnknown = 1 // exitUnknown is returned when no provider was detected.
+	exitError   = 2 // exitError is returned on invalid usage.
+)
+
+// detect runs the detection. It is replaced in tests.
+var detect = clouddetect.DetectResult
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// detectFlags are the flags controlling the detection, shared by every subcommand.
+type detectFlags struct {
+	timeout    *time.Duration
+	include    *string
+	exclude    *string
+	logLevel   *string
+	attest     *bool
+	trust      *string
+	kubernetes *bool
+}
+
+func addDetectFlags(flags *flag.FlagSet) detectFlags {
+	return detectFlags{
+		timeout:    flags.Duration("timeout", clouddetect.DefaultDetectionTimeout, "maximum time allowed for detection"),
+		include:    flags.String("include", "", "comma-separated list of providers to check (default all)"),
+		exclude:    flags.String("exclude", "", "comma-separated list of providers not to check"),
+		logLevel:   flags.String("log-level", "", "log to stderr at this level (debug, info, warn, error)"),
+		attest:     flags.Bool("attest", false, "verify the provider from its signed identity document"),
+		trust:      flags.String("trust", "", "directory of trust material for -attest (default embedded)"),
+		kubernetes: flags.Bool("kubernetes", false, "identify the managed Kubernetes distribution from the provider's metadata"),
+	}
+}
+
+// options returns the detection options set by the flags, and the logger they use. Errors are reported to stderr.
+func (f detectFlags) options(stderr io.Writer) ([]clouddetect.Option, *zap.Logger, bool) {
+	included, err := parseProviders(*f.include)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: -include: %s\n", err)
+		return nil, nil, false
+	}
+
+	excluded, err := parseProviders(*f.exclude)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: -exclude: %s\n", err)
+		return nil, nil, false
+	}
+
+	logger, err := newLogger(*f.logLevel)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: -log-level: %s\n", err)
+		return nil, nil, false
+	}
+
+	opts := []clouddetect.Option{
+		clouddetect.WithTimeout(*f.timeout),
+		clouddetect.WithLogger(logger),
+		clouddetect.WithProviders(included...),
+		clouddetect.WithoutProviders(excluded...),
+	}
+
+	if *f.attest {
+		var trust fs.FS
+		if *f.trust != "" {
+			trust = os.DirFS(*f.trust)
+		}
+		opts = append(opts, clouddetect.WithAttestation(trust))
+	}
+
+	if *f.kubernetes {
+		opts = append(opts, clouddetect.WithKubernetes())
+	}
+
+	return opts, logger, true
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "explain":
+			return explain(args[1:], stdout, stderr)
+		case "serve":
+			return serve(args[1:], stdout, stderr)
+		}
+	}
+
+	flags := flag.NewFlagSet("clouddetect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: clouddetect [flags]\n       clouddetect explain [flags]\n       clouddetect serve [flags]\n\nPrints the cloud service provider of the host.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	detection := addDetectFlags(flags)
+	output := flags.String("output", "text", "output format ("+strings.Join(formats, ", ")+")")
+	quiet := flags.Bool("q", false, "only set the exit status, don't print the result")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if flags.NArg() > 0 {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: unexpected argument %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitError
+	}
+
+	if !slices.Contains(formats, *output) {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: -output: unsupported output format %q\n", *output)
+		return exitError
+	}
+
+	opts, logger, ok := detection.options(stderr)
+	if !ok {
+		return exitError
+	}
+	defer syncLogger(logger)
+
+	result := detect(opts...)
+
+	if !*quiet {
+		if err := newReport(result).write(stdout, *output); err != nil {
+			_, _ = fmt.Fprintf(stderr, "clouddetect: %s\n", err)
+			return exitError
+		}
+	}
+
+	return exitCode(result)
+}
+
+// exitCode returns the exit status for the detection result. Providers only reported with low confidence
+// aren't detected.
+func exitCode(result clouddetect.Result) int {
+	if !result.Detected() {
+		return exitUnknown
+	}
+
+	return exitOK
+}
+
+func syncLogger(logger *zap.Logger) {
+	// Syncing stderr fails on some platforms, and there is nothing left to do about it anyway.
+	_ = logger.Sync()
+}
+
+// parseProviders parses a comma-separated list of provider identifiers.
+func parseProviders(value string) ([]types.ProviderId, error) {
+	var ids []types.ProviderId
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		id := types.ProviderId(name)
+		if !slices.Contains(clouddetect.SupportedProviders, id) {
+			return nil, fmt.Errorf("unsupported provider %q", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// newLogger returns a logger writing to stderr at the given level, or a no-op logger if level is empty.
+func newLogger(level string) (*zap.Logger, error) {
+	if level == "" {
+		return zap.NewNop(), nil
+	}
+
+	l, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := zap.NewDevelopmentConfig()
+	cfg.Level = zap.NewAtomicLevelAt(l)
+
+	return cfg.Build()
+}
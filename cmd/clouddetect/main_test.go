@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func withResult(t *testing.T, result clouddetect.Result) {
+	original := detect
+	detect = func(...clouddetect.Option) clouddetect.Result {
+		return result
+	}
+	t.Cleanup(func() {
+		detect = original
+	})
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		result         clouddetect.Result
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:           "Detected provider",
+			args:           []string{"-timeout", "1s"},
+			result:         clouddetect.Result{Provider: types.Aws, Confidence: types.HighConfidence},
+			expectedCode:   exitOK,
+			expectedOutput: "aws\n",
+		},
+		{
+			name:           "Unknown provider",
+			result:         clouddetect.Result{Provider: types.Unknown},
+			expectedCode:   exitUnknown,
+			expectedOutput: "unknown\n",
+		},
+		{
+			name:           "Low confidence provider",
+			result:         clouddetect.Result{Provider: types.Equinix, Confidence: types.LowConfidence},
+			expectedCode:   exitUnknown,
+			expectedOutput: "unknown\n",
+		},
+		{
+			name:           "Low confidence provider in JSON output",
+			args:           []string{"-output", "json"},
+			result:         clouddetect.Result{Provider: types.Equinix, Confidence: types.LowConfidence},
+			expectedCode:   exitUnknown,
+			expectedOutput: "{\n  \"schema_version\": 1,\n  \"provider\": \"equinix\",\n  \"confidence\": \"low\",\n  \"platform\": \"\",\n  \"kubernetes\": \"\",\n  \"container\": \"\",\n  \"virtualization\": \"\",\n  \"attested\": false,\n  \"metadata\": {\n    \"instance_id\": \"\",\n    \"region\": \"\",\n    \"zone\": \"\",\n    \"project\": \"\"\n  },\n  \"evidence\": []\n}\n",
+		},
+		{
+			name:           "Quiet",
+			args:           []string{"-q"},
+			result:         clouddetect.Result{Provider: types.Gcp, Confidence: types.HighConfidence},
+			expectedCode:   exitOK,
+			expectedOutput: "",
+		},
+		{
+			name:           "Included and excluded providers",
+			args:           []string{"-include", "aws, gcp", "-exclude", "azure", "-log-level", "error"},
+			result:         clouddetect.Result{Provider: types.Gcp, Confidence: types.HighConfidence},
+			expectedCode:   exitOK,
+			expectedOutput: "gcp\n",
+		},
+		{
+			name:           "JSON output",
+			args:           []string{"--output", "json"},
+			result:         clouddetect.Result{Provider: types.Unknown},
+			expectedCode:   exitUnknown,
+			expectedOutput: "{\n  \"schema_version\": 1,\n  \"provider\": \"unknown\",\n  \"confidence\": \"none\",\n  \"platform\": \"\",\n  \"kubernetes\": \"\",\n  \"container\": \"\",\n  \"virtualization\": \"\",\n  \"attested\": false,\n  \"metadata\": {\n    \"instance_id\": \"\",\n    \"region\": \"\",\n    \"zone\": \"\",\n    \"project\": \"\"\n  },\n  \"evidence\": []\n}\n",
+		},
+		{
+			name:         "Unsupported output format",
+			args:         []string{"-output", "xml"},
+			expectedCode: exitError,
+		},
+		{
+			name:         "Unsupported provider",
+			args:         []string{"-include", "aws,heroku"},
+			expectedCode: exitError,
+		},
+		{
+			name:         "Invalid log level",
+			args:         []string{"-log-level", "verbose"},
+			expectedCode: exitError,
+		},
+		{
+			name:         "Unexpected argument",
+			args:         []string{"aws"},
+			expectedCode: exitError,
+		},
+		{
+			name:         "Help",
+			args:         []string{"-h"},
+			expectedCode: exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withResult(t, tt.result)
+
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			if code != tt.expectedCode {
+				t.Errorf("run() = %d; want %d (stderr: %s)", code, tt.expectedCode, stderr.String())
+			}
+			if stdout.String() != tt.expectedOutput {
+				t.Errorf("run() output = %q; want %q", stdout.String(), tt.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestParseProviders(t *testing.T) {
+	ids, err := parseProviders(" aws,,vmware ")
+	if err != nil {
+		t.Fatalf("parseProviders() error = %v", err)
+	}
+
+	if len(ids) != 2 || ids[0] != types.Aws || ids[1] != types.Vmware {
+		t.Errorf("parseProviders() = %v; want [%s %s]", ids, types.Aws, types.Vmware)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// schemaVersion is the version of the report schema. Fields may be added to the report without changing it,
+// but it is incremented whenever a field is removed, renamed or changes meaning.
+const schemaVersion = 1
+
+// formats are the supported output formats.
+var formats = []string{"text", "json", "yaml", "env", "facts"}
+
+// safeValue matches the env output values that can be written without quoting.
+var safeValue = regexp.MustCompile(`^[A-Za-z0-9._:/@+-]*$`)
+
+// envEscaper escapes the characters that are special within double quotes, both to a POSIX shell and in a
+// systemd EnvironmentFile.
+var envEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+
+// report is the machine-readable detection result. Every field is always present, empty if unknown.
+type report struct {
+	SchemaVersion  int            `json:"schema_version" yaml:"schema_version"`
+	Provider       string         `json:"provider" yaml:"provider"`
+	Confidence     string         `json:"confidence" yaml:"confidence"`
+	Platform       string         `json:"platform" yaml:"platform"`
+	Kubernetes     string         `json:"kubernetes" yaml:"kubernetes"`
+	Container      string         `json:"container" yaml:"container"`
+	Virtualization string         `json:"virtualization" yaml:"virtualization"`
+	Attested       bool           `json:"attested" yaml:"attested"`
+	Metadata       reportMetadata `json:"metadata" yaml:"metadata"`
+	Evidence       []reportCheck  `json:"evidence" yaml:"evidence"`
+
+	detected bool // detected reports whether the provider was detected with more than low confidence.
+}
+
+type reportMetadata struct {
+	InstanceID string `json:"instance_id" yaml:"instance_id"`
+	Region     string `json:"region" yaml:"region"`
+	Zone       string `json:"zone" yaml:"zone"`
+	Project    string `json:"project" yaml:"project"`
+}
+
+// reportCheck is a check that matched the detected provider.
+type reportCheck struct {
+	Check  string `json:"check" yaml:"check"`
+	Source string `json:"source" yaml:"source"`
+	Value  string `json:"value" yaml:"value"`
+}
+
+type field struct {
+	key   string
+	value string
+}
+
+func newReport(result clouddetect.Result) report {
+	evidence := make([]reportCheck, 0, len(result.Checks))
+	for _, c := range result.Checks {
+		evidence = append(evidence, reportCheck{Check: c.Check, Source: c.Source, Value: c.Value})
+	}
+
+	return report{
+		SchemaVersion:  schemaVersion,
+		Provider:       string(result.Provider),
+		Confidence:     result.Confidence.String(),
+		Platform:       string(result.Platform),
+		Kubernetes:     string(result.Kubernetes),
+		Container:      string(result.Container),
+		Virtualization: string(result.Virtualization),
+		Attested:       result.Attested,
+		detected:       result.Detected(),
+		Metadata: reportMetadata{
+			InstanceID: result.Metadata.InstanceID,
+			Region:     result.Metadata.Region,
+			Zone:       result.Metadata.Zone,
+			Project:    result.Metadata.Project,
+		},
+		Evidence: evidence,
+	}
+}
+
+// fields returns the report flattened into key/value pairs, in a fixed order.
+func (r report) fields() []field {
+	return []field{
+		{"schema_version", strconv.Itoa(r.SchemaVersion)},
+		{"provider", r.Provider},
+		{"confidence", r.Confidence},
+		{"platform", r.Platform},
+		{"kubernetes", r.Kubernetes},
+		{"container", r.Container},
+		{"virtualization", r.Virtualization},
+		{"attested", strconv.FormatBool(r.Attested)},
+		{"instance_id", r.Metadata.InstanceID},
+		{"region", r.Metadata.Region},
+		{"zone", r.Metadata.Zone},
+		{"project", r.Metadata.Project},
+		{"evidence", r.evidence()},
+	}
+}
+
+// evidence returns the checks that matched as a single value, e.g. "sys_vendor file (/sys/class/dmi/id/sys_vendor)".
+func (r report) evidence() string {
+	checks := make([]string, len(r.Evidence))
+	for i, c := range r.Evidence {
+		checks[i] = fmt.Sprintf("%s (%s)", c.Check, c.Source)
+	}
+
+	return strings.Join(checks, "; ")
+}
+
+// write writes the report to w in the given format.
+func (r report) write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		// Low confidence providers are only hints, which the other formats report with their confidence.
+		provider := r.Provider
+		if !r.detected {
+			provider = string(types.Unknown)
+		}
+		_, err := fmt.Fprintln(w, provider)
+		return err
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "env":
+		return r.writeEnv(w)
+	case "facts":
+		return r.writeFacts(w)
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// writeEnv writes CLOUDDETECT_<KEY>=value lines, which can be evaluated by a POSIX shell or read as a
+// systemd EnvironmentFile. Values are double-quoted unless they only contain safe characters, as both read
+// backslash escapes the same way within double quotes.
+func (r report) writeEnv(w io.Writer) error {
+	for _, f := range r.fields() {
+		value := f.value
+		if !safeValue.MatchString(value) {
+			value = `"` + envEscaper.Replace(value) + `"`
+		}
+
+		if _, err := fmt.Fprintf(w, "CLOUDDETECT_%s=%s\n", strings.ToUpper(f.key), value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFacts writes a flat JSON object, suitable as an Ansible custom fact
+// (e.g. /etc/ansible/facts.d/clouddetect.fact, exposed as ansible_local.clouddetect).
+func (r report) writeFacts(w io.Writer) error {
+	facts := make(map[string]any)
+	for _, f := range r.fields() {
+		facts[f.key] = f.value
+	}
+	facts["schema_version"] = r.SchemaVersion
+	facts["attested"] = r.Attested
+	facts["evidence"] = r.Evidence
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(facts)
+}
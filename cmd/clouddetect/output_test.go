@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+var testResult = clouddetect.Result{
+	Provider:       types.Gcp,
+	Confidence:     types.HighConfidence,
+	Platform:       types.CloudRun,
+	Container:      types.GVisor,
+	Virtualization: types.Kvm,
+	Attested:       true,
+	Metadata: types.Metadata{
+		InstanceID: "4520031799277581759",
+		Region:     "europe-west4",
+		Zone:       "europe-west4-b",
+		Project:    "example.com:my-project",
+	},
+	Checks: []probe.Result{
+		{Provider: types.Gcp, Check: "metadata server", Source: "http://metadata.google.internal/computeMetadata/v1/instance/zone", Value: "200 projects/123/zones/europe-west4-b", Outcome: probe.Pass},
+	},
+}
+
+func TestReportWrite(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		expectedOutput string
+	}{
+		{
+			name:           "Text",
+			format:         "text",
+			expectedOutput: "gcp\n",
+		},
+		{
+			name:   "JSON",
+			format: "json",
+			expectedOutput: `{
+  "schema_version": 1,
+  "provider": "gcp",
+  "confidence": "high",
+  "platform": "cloud-run",
+  "kubernetes": "",
+  "container": "gvisor",
+  "virtualization": "kvm",
+  "attested": true,
+  "metadata": {
+    "instance_id": "4520031799277581759",
+    "region": "europe-west4",
+    "zone": "europe-west4-b",
+    "project": "example.com:my-project"
+  },
+  "evidence": [
+    {
+      "check": "metadata server",
+      "source": "http://metadata.google.internal/computeMetadata/v1/instance/zone",
+      "value": "200 projects/123/zones/europe-west4-b"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:   "YAML",
+			format: "yaml",
+			expectedOutput: `schema_version: 1
+provider: gcp
+confidence: high
+platform: cloud-run
+kubernetes: ""
+container: gvisor
+virtualization: kvm
+attested: true
+metadata:
+  instance_id: "4520031799277581759"
+  region: europe-west4
+  zone: europe-west4-b
+  project: example.com:my-project
+evidence:
+  - check: metadata server
+    source: http://metadata.google.internal/computeMetadata/v1/instance/zone
+    value: 200 projects/123/zones/europe-west4-b
+`,
+		},
+		{
+			name:   "Environment",
+			format: "env",
+			expectedOutput: `CLOUDDETECT_SCHEMA_VERSION=1
+CLOUDDETECT_PROVIDER=gcp
+CLOUDDETECT_CONFIDENCE=high
+CLOUDDETECT_PLATFORM=cloud-run
+CLOUDDETECT_KUBERNETES=
+CLOUDDETECT_CONTAINER=gvisor
+CLOUDDETECT_VIRTUALIZATION=kvm
+CLOUDDETECT_ATTESTED=true
+CLOUDDETECT_INSTANCE_ID=4520031799277581759
+CLOUDDETECT_REGION=europe-west4
+CLOUDDETECT_ZONE=europe-west4-b
+CLOUDDETECT_PROJECT=example.com:my-project
+CLOUDDETECT_EVIDENCE="metadata server (http://metadata.google.internal/computeMetadata/v1/instance/zone)"
+`,
+		},
+		{
+			name:   "Ansible facts",
+			format: "facts",
+			expectedOutput: `{
+  "attested": true,
+  "confidence": "high",
+  "container": "gvisor",
+  "evidence": [
+    {
+      "check": "metadata server",
+      "source": "http://metadata.google.internal/computeMetadata/v1/instance/zone",
+      "value": "200 projects/123/zones/europe-west4-b"
+    }
+  ],
+  "instance_id": "4520031799277581759",
+  "kubernetes": "",
+  "platform": "cloud-run",
+  "project": "example.com:my-project",
+  "provider": "gcp",
+  "region": "europe-west4",
+  "schema_version": 1,
+  "virtualization": "kvm",
+  "zone": "europe-west4-b"
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := newReport(testResult).write(&out, tt.format); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+
+			if out.String() != tt.expectedOutput {
+				t.Errorf("write() output =\n%s\nwant\n%s", out.String(), tt.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestReportWriteEnvQuotesValues(t *testing.T) {
+	tests := []struct {
+		zone     string
+		expected string
+	}{
+		{zone: "it's a zone", expected: `CLOUDDETECT_ZONE="it's a zone"`},
+		{zone: `say "$HOME" \ ` + "`id`", expected: `CLOUDDETECT_ZONE="say \"\$HOME\" \\ \` + "`id\\`" + `"`},
+	}
+
+	for _, tt := range tests {
+		r := newReport(clouddetect.Result{Provider: types.Unknown, Metadata: types.Metadata{Zone: tt.zone}})
+
+		var out bytes.Buffer
+		if err := r.writeEnv(&out); err != nil {
+			t.Fatalf("writeEnv() error = %v", err)
+		}
+
+		if want := tt.expected + "\n"; !bytes.Contains(out.Bytes(), []byte(want)) {
+			t.Errorf("writeEnv() output =\n%s\nwant line %q", out.String(), want)
+		}
+	}
+}
+
+func TestReportWriteUnsupportedFormat(t *testing.T) {
+	var out bytes.Buffer
+	if err := newReport(testResult).write(&out, "xml"); err == nil {
+		t.Error("Expected write() to fail for an unsupported format")
+	}
+}
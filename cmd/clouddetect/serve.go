@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+)
+
+// defaultListenAddress is the address the daemon listens on by default.
+const defaultListenAddress = "127.0.0.1:7070"
+
+// shutdownTimeout is the time allowed for in-flight requests to complete on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// snapshot is the cached result of a detection.
+type snapshot struct {
+	Report     report      `json:"result"`
+	Checks     []checkJSON `json:"checks"`
+	DetectedAt time.Time   `json:"detected_at"`
+}
+
+// checkJSON is a probe.Result as served by /v1/evidence.
+type checkJSON struct {
+	Provider string `json:"provider"`
+	Check    string `json:"check"`
+	Source   string `json:"source"`
+	Duration string `json:"duration"`
+	Value    string `json:"value"`
+	Outcome  string `json:"outcome"`
+	Error    string `json:"error,omitempty"`
+}
+
+// server serves the cached detection result. The detection only runs on start and when a refresh is requested.
+type server struct {
+	detect  func(...clouddetect.Option) clouddetect.Result
+	opts    []clouddetect.Option
+	explain bool     // explain records every check the providers ran, waiting for all of them to finish.
+	hosts   []string // hosts are the accepted Host headers, or empty to accept any (e.g. on a Unix socket).
+	logger  *zap.Logger
+
+	refreshMu sync.Mutex // refreshMu serializes detections.
+	mu        sync.RWMutex
+	snapshot  snapshot
+}
+
+func newServer(opts []clouddetect.Option, explain bool, hosts []string, logger *zap.Logger) *server {
+	return &server{detect: detect, opts: opts, explain: explain, hosts: hosts, logger: logger}
+}
+
+// refresh runs the detection and replaces the cached result. Every check is only recorded in explain mode,
+// as the detection then waits for every provider to finish rather than returning as soon as one is detected.
+func (s *server) refresh() snapshot {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	opts := s.opts
+	recorder := &probe.Recorder{}
+	if s.explain {
+		opts = append(slices.Clip(opts), clouddetect.WithRecorder(recorder))
+	}
+	result := s.detect(opts...)
+
+	checks := make([]checkJSON, 0)
+	for _, r := range recorder.Results() {
+		checks = append(checks, checkJSON{
+			Provider: string(r.Provider),
+			Check:    r.Check,
+			Source:   r.Source,
+			Duration: r.Duration.String(),
+			Value:    r.Value,
+			Outcome:  string(r.Outcome),
+			Error:    r.Err,
+		})
+	}
+
+	snap := snapshot{Report: newReport(result), Checks: checks, DetectedAt: time.Now().UTC()}
+	s.logger.Info(fmt.Sprintf("Detected cloud service provider: %s", snap.Report.Provider))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = snap
+	return snap
+}
+
+func (s *server) current() snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshot
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, "ok\n")
+	})
+
+	mux.HandleFunc("GET /v1/provider", func(w http.ResponseWriter, r *http.Request) {
+		rep := s.current().Report
+		writeJSON(w, r, map[string]string{
+			"provider":   rep.Provider,
+			"confidence": rep.Confidence,
+			"platform":   rep.Platform,
+		})
+	})
+
+	mux.HandleFunc("GET /v1/metadata", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, s.current().Report.Metadata)
+	})
+
+	mux.HandleFunc("GET /v1/evidence", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, s.current())
+	})
+
+	mux.HandleFunc("POST /v1/refresh", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, s.refresh())
+	})
+
+	return s.checkOrigin(mux)
+}
+
+// checkOrigin rejects requests for another host, which a browser sends to a page whose DNS record was rebound
+// to the loopback address, and requests other than GET sent by pages from another origin (cross-site request
+// forgery). Browsers can't connect to a Unix socket, so any host is accepted there.
+func (s *server) checkOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.hosts) > 0 {
+			if !slices.Contains(s.hosts, withPort(r.Host)) {
+				http.Error(w, "unexpected Host header", http.StatusForbidden)
+				return
+			}
+
+			if origin := r.Header.Get("Origin"); origin != "" && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				u, err := url.Parse(origin)
+				if err != nil || !slices.Contains(s.hosts, withPort(u.Host)) {
+					http.Error(w, "cross-origin request", http.StatusForbidden)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withPort returns host with the default HTTP port added if it has none.
+func withPort(host string) string {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		return net.JoinHostPort(strings.Trim(host, "[]"), "80")
+	}
+
+	return host
+}
+
+// listenHosts returns the Host headers accepted for requests to the listener's address: the address itself,
+// and localhost with the same port. It returns nil for a Unix socket.
+func listenHosts(addr net.Addr) []string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return nil
+	}
+
+	port := strconv.Itoa(tcpAddr.Port)
+	return []string{tcpAddr.String(), net.JoinHostPort("localhost", port)}
+}
+
+// writeJSON writes v as JSON with an ETag derived from its content, answering 304 Not Modified when it
+// matches the If-None-Match header of a GET request.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method == http.MethodGet && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag, using weak comparison.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listen listens on a Unix socket for addresses of the form unix:/path, and on a loopback TCP address otherwise.
+// A stale socket file left behind by a previous run is removed, but no other kind of file.
+func listen(address string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		fi, err := os.Lstat(path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, err
+		case fi.Mode()&os.ModeSocket == 0:
+			return nil, fmt.Errorf("%s is in use and is not a socket", path)
+		default:
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+		return net.Listen("unix", path)
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if host != "localhost" {
+		addr, err := netip.ParseAddr(host)
+		if err != nil || !addr.IsLoopback() {
+			return nil, fmt.Errorf("%q is not a loopback address", host)
+		}
+	}
+
+	return net.Listen("tcp", address)
+}
+
+// serve runs the detection and serves its result over HTTP until interrupted.
+// SIGHUP runs the detection again.
+func serve(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("clouddetect serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: clouddetect serve [flags]\n\nDetects the cloud service provider of the host once and serves the result over HTTP.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+
+	detection := addDetectFlags(flags)
+	address := flags.String("listen", defaultListenAddress, "loopback address or unix:/path of the socket to listen on")
+	explainChecks := flags.Bool("explain", false, "serve every check the providers ran on /v1/evidence (detection waits for every provider)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitError
+	}
+
+	if flags.NArg() > 0 {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: unexpected argument %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitError
+	}
+
+	opts, logger, ok := detection.options(stderr)
+	if !ok {
+		return exitError
+	}
+	defer syncLogger(logger)
+
+	listener, err := listen(*address)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: -listen: %s\n", err)
+		return exitError
+	}
+
+	s := newServer(opts, *explainChecks, listenHosts(listener.Addr()), logger)
+	s.refresh()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				s.refresh()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	httpServer := &http.Server{Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if shutdownErr := httpServer.Shutdown(shutdownCtx); shutdownErr != nil {
+			logger.Error(fmt.Sprintf("Error shutting down server: %s", shutdownErr))
+		}
+	}()
+
+	_, _ = fmt.Fprintf(stdout, "Listening on %s\n", listener.Addr())
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		_, _ = fmt.Fprintf(stderr, "clouddetect: %s\n", err)
+		return exitError
+	}
+	<-shutdown
+
+	return exitOK
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// newTestServer returns a server whose detections return the given results in turn, repeating the last one.
+func newTestServer(t *testing.T, results ...clouddetect.Result) *server {
+	calls := 0
+	original := detect
+	detect = func(...clouddetect.Option) clouddetect.Result {
+		result := results[min(calls, len(results)-1)]
+		calls++
+		return result
+	}
+	t.Cleanup(func() {
+		detect = original
+	})
+
+	s := newServer(nil, false, nil, zap.NewNop())
+	s.refresh()
+
+	return s
+}
+
+func TestServerEndpoints(t *testing.T) {
+	s := newTestServer(t, clouddetect.Result{
+		Provider:   types.Gcp,
+		Confidence: types.HighConfidence,
+		Metadata:   types.Metadata{InstanceID: "123", Region: "us-central1", Zone: "us-central1-a", Project: "my-project"},
+	})
+
+	tests := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedContains string
+	}{
+		{name: "Health", path: "/healthz", expectedStatus: http.StatusOK, expectedContains: "ok"},
+		{name: "Provider", path: "/v1/provider", expectedStatus: http.StatusOK, expectedContains: `"provider": "gcp"`},
+		{name: "Metadata", path: "/v1/metadata", expectedStatus: http.StatusOK, expectedContains: `"project": "my-project"`},
+		{name: "Evidence", path: "/v1/evidence", expectedStatus: http.StatusOK, expectedContains: `"confidence": "high"`},
+		{name: "Unknown path", path: "/v1/unknown", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.handler().ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("GET %s status = %d; want %d", tt.path, rec.Code, tt.expectedStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedContains) {
+				t.Errorf("GET %s body = %q; want it to contain %q", tt.path, rec.Body.String(), tt.expectedContains)
+			}
+		})
+	}
+}
+
+func TestServerETag(t *testing.T) {
+	s := newTestServer(t,
+		clouddetect.Result{Provider: types.Unknown},
+		clouddetect.Result{Provider: types.Aws, Confidence: types.HighConfidence},
+	)
+	handler := s.handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/provider", nil))
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("GET /v1/provider returned no ETag")
+	}
+
+	req := httptest.NewRequest("GET", "/v1/provider", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("GET /v1/provider with matching If-None-Match = %d %q; want %d", rec.Code, rec.Body.String(), http.StatusNotModified)
+	}
+
+	// Detecting again changes the result, and with it the ETag.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/v1/refresh", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /v1/refresh status = %d; want %d", rec.Code, http.StatusOK)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &snap); err != nil {
+		t.Fatalf("POST /v1/refresh returned invalid JSON: %v", err)
+	}
+	if snap.Report.Provider != string(types.Aws) {
+		t.Errorf("POST /v1/refresh provider = %q; want %q", snap.Report.Provider, types.Aws)
+	}
+
+	req = httptest.NewRequest("GET", "/v1/provider", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"provider": "aws"`) {
+		t.Errorf("GET /v1/provider after refresh = %d %q; want the new result", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServerChecksOrigin(t *testing.T) {
+	s := newTestServer(t, clouddetect.Result{Provider: types.Aws, Confidence: types.HighConfidence})
+	s.hosts = listenHosts(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7070})
+	handler := s.handler()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		host           string
+		origin         string
+		expectedStatus int
+	}{
+		{name: "Listen address", method: "GET", path: "/v1/provider", host: "127.0.0.1:7070", expectedStatus: http.StatusOK},
+		{name: "Localhost", method: "GET", path: "/v1/provider", host: "localhost:7070", expectedStatus: http.StatusOK},
+		{name: "Rebound DNS name", method: "GET", path: "/v1/evidence", host: "attacker.example:7070", expectedStatus: http.StatusForbidden},
+		{name: "Host without port", method: "GET", path: "/v1/provider", host: "127.0.0.1", expectedStatus: http.StatusForbidden},
+		{name: "Refresh without origin", method: "POST", path: "/v1/refresh", host: "127.0.0.1:7070", expectedStatus: http.StatusOK},
+		{name: "Refresh from the same origin", method: "POST", path: "/v1/refresh", host: "127.0.0.1:7070", origin: "http://127.0.0.1:7070", expectedStatus: http.StatusOK},
+		{name: "Refresh from another origin", method: "POST", path: "/v1/refresh", host: "127.0.0.1:7070", origin: "https://attacker.example", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("%s %s with Host %q status = %d; want %d", tt.method, tt.path, tt.host, rec.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestServerExplain(t *testing.T) {
+	var options []int
+	original := detect
+	detect = func(opts ...clouddetect.Option) clouddetect.Result {
+		options = append(options, len(opts))
+		return clouddetect.Result{Provider: types.Aws, Confidence: types.HighConfidence}
+	}
+	t.Cleanup(func() {
+		detect = original
+	})
+
+	// A recorder is only attached in explain mode, as detection then waits for every provider.
+	newServer(nil, false, nil, zap.NewNop()).refresh()
+	newServer(nil, true, nil, zap.NewNop()).refresh()
+
+	if want := []int{0, 1}; !slices.Equal(options, want) {
+		t.Errorf("detection options = %v; want %v", options, want)
+	}
+}
+
+func TestListenHosts(t *testing.T) {
+	if hosts := listenHosts(&net.TCPAddr{IP: net.IPv6loopback, Port: 7070}); !slices.Equal(hosts, []string{"[::1]:7070", "localhost:7070"}) {
+		t.Errorf("listenHosts(tcp) = %q; want the address and localhost", hosts)
+	}
+	if hosts := listenHosts(&net.UnixAddr{Name: "/run/clouddetect.sock", Net: "unix"}); hosts != nil {
+		t.Errorf("listenHosts(unix) = %q; want nil", hosts)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: `"abc"`, expected: true},
+		{header: `W/"abc"`, expected: true},
+		{header: `"xyz", "abc"`, expected: true},
+		{header: `*`, expected: true},
+		{header: `"xyz"`, expected: false},
+		{header: ``, expected: false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"abc"`); got != tt.expected {
+			t.Errorf("etagMatches(%q) = %v; want %v", tt.header, got, tt.expected)
+		}
+	}
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name        string
+		address     string
+		expectedErr bool
+	}{
+		{name: "Loopback address", address: "127.0.0.1:0"},
+		{name: "Localhost", address: "localhost:0"},
+		{name: "Unix socket", address: "unix:" + filepath.Join(t.TempDir(), "clouddetect.sock")},
+		{name: "All interfaces", address: ":0", expectedErr: true},
+		{name: "Public address", address: "192.0.2.1:7070", expectedErr: true},
+		{name: "Missing port", address: "127.0.0.1", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := listen(tt.address)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("listen(%q) error = %v; want error %v", tt.address, err, tt.expectedErr)
+			}
+			if listener != nil {
+				_ = listener.Close()
+			}
+		})
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	// A socket left behind by a previous run is replaced.
+	stale := filepath.Join(dir, "stale.sock")
+	listener, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = listener.Close()
+
+	listener, err = listen("unix:" + stale)
+	if err != nil {
+		t.Fatalf("listen() with a stale socket error = %v; want nil", err)
+	}
+	_ = listener.Close()
+
+	// Any other file is left intact.
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("content"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if listener, err := listen("unix:" + file); err == nil {
+		_ = listener.Close()
+		t.Fatal("listen() with a regular file error = nil; want an error")
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != "content" {
+		t.Errorf("listen() with a regular file changed it: %q, %v", content, err)
+	}
+}
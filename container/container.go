@@ -0,0 +1,190 @@
+// Package container implements detection of the container or sandbox runtime the host runs in.
+//
+// All files are read relative to a root directory, so the detection can run against a copy of
+// another host's filesystem (or test fixtures) as well as against "/".
+package container
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	dockerEnvFile    = "/.dockerenv"
+	containerEnvFile = "/run/.containerenv"
+	cgroupFile       = "/proc/1/cgroup"
+	mountInfoFile    = "/proc/self/mountinfo"
+	versionFile      = "/proc/version"
+	cmdlineFile      = "/proc/cmdline"
+)
+
+// gVisorVersion is the fixed kernel build string reported in /proc/version by gVisor.
+const gVisorVersion = "#1 SMP Sun Jan 10 15:06:54 PST 2016"
+
+// kataUnit is the systemd unit Kata Containers boots its guest VM into (systemd.unit=kata-containers.target).
+const kataUnit = "kata-containers"
+
+// kataAgentParams are the kernel command line parameters configuring the Kata Containers agent in its guest VM.
+var kataAgentParams = []string{
+	"agent.log",
+	"agent.log_vport",
+	"agent.debug_console",
+	"agent.debug_console_vport",
+	"agent.trace",
+	"agent.devmode",
+	"agent.container_pipe_size",
+	"agent.hotplug_timeout",
+	"agent.unified_cgroup_hierarchy",
+	"agent.passfd_listener_port",
+	"agent.server_addr",
+}
+
+type marker struct {
+	value   string
+	runtime types.ContainerRuntime
+}
+
+// cgroupMarkers are cgroup path fragments written by container runtimes. They are checked in order,
+// so the runtimes built on top of containerd come before containerd itself.
+var cgroupMarkers = []marker{
+	{"libpod", types.Podman},
+	{"crio-", types.CriO},
+	{"cri-containerd", types.Containerd},
+	{"docker", types.Docker},
+	{"containerd", types.Containerd},
+	{"/lxc", types.Lxc},
+	{"kubepods", types.Container},
+}
+
+// mountMarkers are storage paths of container runtimes that show up in the root mount of their containers.
+// Hosts running containers have mounts under the same paths, so only the root mount is checked.
+var mountMarkers = []marker{
+	{"/var/lib/docker/", types.Docker},
+	{"io.containerd.", types.Containerd},
+	{"/var/lib/containers/storage/", types.Podman},
+}
+
+// Detect returns the container or sandbox runtime the host runs in, reading all files under root.
+func Detect(root string, logger *zap.Logger) types.ContainerRuntime {
+	// Sandboxes run containers of other runtimes, so they are checked first.
+	if checkVersionFile(filepath.Join(root, versionFile), logger) {
+		return types.GVisor
+	}
+
+	if checkCmdlineFile(filepath.Join(root, cmdlineFile), logger) {
+		return types.Kata
+	}
+
+	if checkEnvFile(filepath.Join(root, containerEnvFile), logger) {
+		return types.Podman
+	}
+
+	if checkEnvFile(filepath.Join(root, dockerEnvFile), logger) {
+		return types.Docker
+	}
+
+	if runtime, ok := checkMarkers(filepath.Join(root, cgroupFile), cgroupMarkers, logger); ok {
+		return runtime
+	}
+
+	if runtime, ok := checkMountInfoFile(filepath.Join(root, mountInfoFile), logger); ok {
+		return runtime
+	}
+
+	return types.NoContainer
+}
+
+func checkVersionFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s version file %s", types.GVisor, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(string(content), gVisorVersion)
+}
+
+func checkCmdlineFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s kernel command line file %s", types.Kata, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	for _, param := range strings.Fields(string(content)) {
+		key, value, _ := strings.Cut(param, "=")
+		if slices.Contains(kataAgentParams, key) || (key == "systemd.unit" && strings.HasPrefix(value, kataUnit)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkEnvFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking container environment file %s", file))
+
+	if _, err := os.Stat(file); err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return true
+}
+
+func checkMarkers(file string, markers []marker, logger *zap.Logger) (types.ContainerRuntime, bool) {
+	logger.Debug(fmt.Sprintf("Checking container runtime markers in file %s", file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return types.NoContainer, false
+	}
+
+	for _, m := range markers {
+		if strings.Contains(string(content), m.value) {
+			return m.runtime, true
+		}
+	}
+
+	return types.NoContainer, false
+}
+
+// checkMountInfoFile checks the root mount in the mount information file (see proc_pid_mountinfo(5)) for the
+// storage paths of container runtimes.
+func checkMountInfoFile(file string, logger *zap.Logger) (types.ContainerRuntime, bool) {
+	logger.Debug(fmt.Sprintf("Checking container runtime markers in the root mount of file %s", file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return types.NoContainer, false
+	}
+
+	// Mounts stacked on the same mount point are listed in order, so the last root mount is the visible one.
+	var rootMount string
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) > 4 && fields[4] == "/" {
+			rootMount = line
+		}
+	}
+
+	for _, m := range mountMarkers {
+		if strings.Contains(rootMount, m.value) {
+			return m.runtime, true
+		}
+	}
+
+	return types.NoContainer, false
+}
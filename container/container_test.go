@@ -0,0 +1,207 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name            string
+		root            string
+		expectedRuntime types.ContainerRuntime
+	}{
+		{name: "Host", root: "host", expectedRuntime: types.NoContainer},
+		{name: "Docker environment file", root: "docker", expectedRuntime: types.Docker},
+		{name: "Podman environment file", root: "podman", expectedRuntime: types.Podman},
+		{name: "containerd cgroup", root: "containerd", expectedRuntime: types.Containerd},
+		{name: "CRI-O cgroup", root: "crio", expectedRuntime: types.CriO},
+		{name: "gVisor kernel version", root: "gvisor", expectedRuntime: types.GVisor},
+		{name: "Kata kernel command line", root: "kata", expectedRuntime: types.Kata},
+		{name: "Docker overlay mount", root: "mountinfo", expectedRuntime: types.Docker},
+		{name: "Host running containers", root: "node", expectedRuntime: types.NoContainer},
+		{name: "Missing root", root: "missing", expectedRuntime: types.NoContainer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			result := Detect(filepath.Join("testdata", tt.root), logger)
+
+			if result != tt.expectedRuntime {
+				t.Errorf("Detect() = %v; want %v", result, tt.expectedRuntime)
+			}
+		})
+	}
+}
+
+func TestCheckMarkers(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileContent     string
+		expectedRuntime types.ContainerRuntime
+		expectedResult  bool
+	}{
+		{
+			name:            "Docker cgroupfs driver",
+			fileContent:     "12:memory:/docker/3f1b2a\n",
+			expectedRuntime: types.Docker,
+			expectedResult:  true,
+		},
+		{
+			name:            "Podman systemd driver",
+			fileContent:     "0::/machine.slice/libpod-3f1b2a.scope/container\n",
+			expectedRuntime: types.Podman,
+			expectedResult:  true,
+		},
+		{
+			name:            "LXC container",
+			fileContent:     "0::/lxc.payload.web\n",
+			expectedRuntime: types.Lxc,
+			expectedResult:  true,
+		},
+		{
+			name:            "Kubernetes pod with unknown runtime",
+			fileContent:     "11:cpu:/kubepods/besteffort/pod9c2d/8a7b6c\n",
+			expectedRuntime: types.Container,
+			expectedResult:  true,
+		},
+		{
+			name:            "Host cgroup",
+			fileContent:     "0::/init.scope\n",
+			expectedRuntime: types.NoContainer,
+			expectedResult:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			logger := zap.NewNop()
+			runtime, result := checkMarkers(tmpFile, cgroupMarkers, logger)
+
+			if runtime != tt.expectedRuntime || result != tt.expectedResult {
+				t.Errorf("checkMarkers() = %v, %v; want %v, %v", runtime, result, tt.expectedRuntime, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMountInfoFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileContent     string
+		expectedRuntime types.ContainerRuntime
+		expectedResult  bool
+	}{
+		{
+			name:            "containerd root mount",
+			fileContent:     "701 640 0:60 / / rw,relatime - overlay overlay rw,lowerdir=/var/lib/containerd/io.containerd.snapshotter.v1.overlayfs/snapshots/12/fs\n",
+			expectedRuntime: types.Containerd,
+			expectedResult:  true,
+		},
+		{
+			name: "Stacked root mounts",
+			fileContent: "22 1 259:2 / / rw,relatime - ext4 /dev/nvme0n1p2 rw\n" +
+				"723 22 0:70 / / rw,relatime - overlay overlay rw,lowerdir=/var/lib/containers/storage/overlay/l/ABC\n",
+			expectedRuntime: types.Podman,
+			expectedResult:  true,
+		},
+		{
+			name: "Container mounts of the host",
+			fileContent: "22 1 259:2 / / rw,relatime - ext4 /dev/nvme0n1p2 rw\n" +
+				"612 22 0:52 / /var/lib/docker/overlay2/0f1e/merged rw,relatime - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/ABC\n",
+			expectedRuntime: types.NoContainer,
+			expectedResult:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			logger := zap.NewNop()
+			runtime, result := checkMountInfoFile(tmpFile, logger)
+
+			if runtime != tt.expectedRuntime || result != tt.expectedResult {
+				t.Errorf("checkMountInfoFile() = %v, %v; want %v, %v", runtime, result, tt.expectedRuntime, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckCmdlineFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Kata systemd unit",
+			fileContent:    "root=/dev/vda1 ro systemd.unit=kata-containers.target\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Kata agent parameter",
+			fileContent:    "console=hvc0 root=/dev/pmem0p1 agent.log_vport=1025\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other agent parameters",
+			fileContent:    "root=/dev/sda1 ro foo.agent.enabled=1 agent.example=1 initrd=/boot/agent.img\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			logger := zap.NewNop()
+			if result := checkCmdlineFile(tmpFile, logger); result != tt.expectedResult {
+				t.Errorf("checkCmdlineFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
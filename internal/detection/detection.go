@@ -0,0 +1,74 @@
+// Package detection shares the state of a detection run between the providers taking part in it.
+//
+// Providers built on another provider's platform (e.g. OVHcloud on OpenStack) defer to it when it matches the
+// host. The run makes sure they only do so when it takes part in the detection, and that its checks only run once.
+package detection
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// Run is the state of a single detection run.
+type Run struct {
+	enabled func(types.ProviderId) bool
+
+	mu      sync.Mutex
+	matches map[types.ProviderId]*match
+}
+
+type match struct {
+	once       sync.Once
+	confidence types.Confidence
+}
+
+type runKey struct{}
+
+// NewRun returns the state of a detection run of the providers enabled reports true for.
+func NewRun(enabled func(types.ProviderId) bool) *Run {
+	return &Run{enabled: enabled, matches: make(map[types.ProviderId]*match)}
+}
+
+// WithRun returns a copy of ctx that carries the detection run.
+func WithRun(ctx context.Context, run *Run) context.Context {
+	return context.WithValue(ctx, runKey{}, run)
+}
+
+func runFrom(ctx context.Context) *Run {
+	run, _ := ctx.Value(runKey{}).(*Run)
+	return run
+}
+
+// Enabled reports whether the provider takes part in the detection run. Outside of a run, every provider does.
+func Enabled(ctx context.Context, id types.ProviderId) bool {
+	run := runFrom(ctx)
+	return run == nil || run.enabled(id)
+}
+
+// Match returns the confidence fn reports the provider matches the host with. fn runs at most once per detection
+// run, and callers asking while it runs wait for its result. Providers that don't take part in the run don't
+// match, and fn isn't run for them. Outside of a run, fn runs on every call.
+func Match(ctx context.Context, id types.ProviderId, fn func() types.Confidence) types.Confidence {
+	run := runFrom(ctx)
+	if run == nil {
+		return fn()
+	}
+
+	if !run.enabled(id) {
+		return types.NoConfidence
+	}
+
+	run.mu.Lock()
+	m, ok := run.matches[id]
+	if !ok {
+		m = new(match)
+		run.matches[id] = m
+	}
+	run.mu.Unlock()
+
+	m.once.Do(func() { m.confidence = fn() })
+
+	return m.confidence
+}
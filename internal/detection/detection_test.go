@@ -0,0 +1,70 @@
+package detection
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func TestMatch(t *testing.T) {
+	var calls atomic.Int32
+	fn := func() types.Confidence {
+		calls.Add(1)
+		return types.MediumConfidence
+	}
+
+	ctx := WithRun(context.Background(), NewRun(func(types.ProviderId) bool { return true }))
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if confidence := Match(ctx, types.Ovh, fn); confidence != types.MediumConfidence {
+				t.Errorf("Match() = %v; want %v", confidence, types.MediumConfidence)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Match() ran the checks %d times; want 1", n)
+	}
+}
+
+func TestMatchExcluded(t *testing.T) {
+	ctx := WithRun(context.Background(), NewRun(func(id types.ProviderId) bool { return id != types.Ovh }))
+
+	if Enabled(ctx, types.Ovh) {
+		t.Errorf("Enabled(%s) = true; want false", types.Ovh)
+	}
+
+	confidence := Match(ctx, types.Ovh, func() types.Confidence {
+		t.Error("Match() ran the checks of an excluded provider")
+		return types.HighConfidence
+	})
+	if confidence != types.NoConfidence {
+		t.Errorf("Match() = %v; want %v", confidence, types.NoConfidence)
+	}
+}
+
+func TestMatchWithoutRun(t *testing.T) {
+	var calls int
+	fn := func() types.Confidence {
+		calls++
+		return types.HighConfidence
+	}
+
+	if !Enabled(context.Background(), types.Ovh) {
+		t.Errorf("Enabled(%s) = false; want true outside of a run", types.Ovh)
+	}
+
+	Match(context.Background(), types.Ovh, fn)
+	Match(context.Background(), types.Ovh, fn)
+	if calls != 2 {
+		t.Errorf("Match() ran the checks %d times; want 2 outside of a run", calls)
+	}
+}
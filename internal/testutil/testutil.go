@@ -0,0 +1,27 @@
+// Package testutil provides helpers shared by the tests of the detection packages.
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// CreateRoot creates a temporary filesystem root holding the given files, keyed by their absolute path
+// (e.g. /sys/class/dmi/id/sys_vendor), and returns it. It is removed when the test completes.
+func CreateRoot(t testing.TB, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	return root
+}
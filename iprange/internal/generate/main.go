@@ -0,0 +1,157 @@
+// Command generate refreshes the snapshots of the published IP address ranges embedded in package iprange.
+//
+// Usage (from the iprange directory, see the go:generate directive there):
+//
+//	go run ./internal/generate [-dir data]
+//
+// Each file is downloaded, parsed and checked to hold at least one range before it replaces the snapshot,
+// so a failed or truncated download never replaces a good one.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
+)
+
+// azureDetailsURL is the Microsoft Download Center page of the Azure Service Tags (Public Cloud). The file
+// it links to is renamed every week, so its URL is read from the page.
+const azureDetailsURL = "https://www.microsoft.com/en-us/download/details.aspx?id=56519"
+
+var azureFilePattern = regexp.MustCompile(`https://download\.microsoft\.com/download/[^"'\s]+/ServiceTags_Public_\d+\.json`)
+
+// sources are the snapshots and where to download them. The URL of those without one is found by resolve.
+var sources = []struct {
+	name    string
+	url     string
+	resolve func(ctx context.Context, client *http.Client) (string, error)
+	parse   func(io.Reader) ([]iprange.Range, error)
+}{
+	{name: "aws.json", url: "https://ip-ranges.amazonaws.com/ip-ranges.json", parse: iprange.ParseAWS},
+	{name: "gcp.json", url: "https://www.gstatic.com/ipranges/cloud.json", parse: iprange.ParseGCP},
+	{name: "azure.json", resolve: resolveAzure, parse: iprange.ParseAzure},
+	{name: "oracle.json", url: "https://docs.oracle.com/en-us/iaas/tools/public_ip_ranges.json", parse: iprange.ParseOracle},
+	{name: "digitalocean.csv", url: "https://digitalocean.com/geo/google.csv", parse: iprange.ParseDigitalOcean},
+}
+
+func main() {
+	dir := flag.String("dir", "data", "directory of the snapshots")
+	timeout := flag.Duration("timeout", 2*time.Minute, "maximum time allowed for each download")
+	flag.Parse()
+
+	client := &http.Client{}
+	failed := false
+	for _, source := range sources {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		n, err := refresh(ctx, client, *dir, source.name, source.url, source.resolve, source.parse)
+		cancel()
+
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "generate: %s: %s\n", source.name, err)
+			failed = true
+			continue
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "generate: %s: %d ranges\n", source.name, n)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// refresh downloads a snapshot and replaces the one in dir, if it holds at least one range. It returns the
+// number of ranges.
+func refresh(
+	ctx context.Context,
+	client *http.Client,
+	dir string,
+	name string,
+	url string,
+	resolve func(context.Context, *http.Client) (string, error),
+	parse func(io.Reader) ([]iprange.Range, error),
+) (int, error) {
+	if resolve != nil {
+		var err error
+		if url, err = resolve(ctx, client); err != nil {
+			return 0, err
+		}
+	}
+
+	content, err := download(ctx, client, url)
+	if err != nil {
+		return 0, err
+	}
+
+	ranges, err := parse(bytes.NewReader(content))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", url, err)
+	}
+	if len(ranges) == 0 {
+		return 0, fmt.Errorf("%s: no ranges found", url)
+	}
+
+	// Write next to the snapshot and rename, so an interrupted run leaves it intact.
+	tmp, err := os.CreateTemp(dir, name+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(content)
+	if err = errors.Join(err, tmp.Close()); err != nil {
+		return 0, err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return 0, err
+	}
+
+	return len(ranges), os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
+
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: response status code %d", url, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// resolveAzure returns the URL of the current Azure Service Tags file.
+func resolveAzure(ctx context.Context, client *http.Client) (string, error) {
+	page, err := download(ctx, client, azureDetailsURL)
+	if err != nil {
+		return "", err
+	}
+
+	url := azureFilePattern.Find(page)
+	if url == nil {
+		return "", fmt.Errorf("%s: no ServiceTags_Public file linked", azureDetailsURL)
+	}
+
+	return string(url), nil
+}
@@ -0,0 +1,185 @@
+// Package iprange attributes IP addresses to cloud service providers, from the IP address ranges they publish.
+//
+// It works offline, from snapshots of the published range files embedded in the package, refreshed with
+// go generate. They can also be refreshed from files on disk with LoadDir and SetDefault, without rebuilding.
+package iprange
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/netip"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+//go:generate go run ./internal/generate -dir data
+
+//go:embed data
+var embedded embed.FS
+
+// sources are the range files read by Load, with the parsers of their format.
+var sources = []struct {
+	name  string
+	parse func(io.Reader) ([]Range, error)
+}{
+	{"aws.json", ParseAWS},
+	{"gcp.json", ParseGCP},
+	{"azure.json", ParseAzure},
+	{"oracle.json", ParseOracle},
+	{"digitalocean.csv", ParseDigitalOcean},
+}
+
+// Range is an IP address range of a cloud service provider.
+type Range struct {
+	Prefix   netip.Prefix     // Prefix is the address range.
+	Provider types.ProviderId // Provider is the cloud service provider that owns the range.
+	Region   string           // Region is the region the range is used in, if any (e.g. us-east-1).
+	Service  string           // Service is the service the range is used by, if any (e.g. EC2).
+}
+
+// node is a node of a binary trie, indexed by the bits of the address.
+type node struct {
+	children [2]*node
+	rng      *Range
+}
+
+// Table is a set of ranges, looked up by longest prefix match. The zero value is an empty table.
+type Table struct {
+	v4 node
+	v6 node
+}
+
+// Insert adds the range to the table, replacing any range with the same prefix.
+func (t *Table) Insert(r Range) {
+	prefix := r.Prefix.Masked()
+	n := &t.v6
+	if prefix.Addr().Is4() {
+		n = &t.v4
+	}
+
+	addr := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		bit := addr[i/8] >> (7 - i%8) & 1
+		if n.children[bit] == nil {
+			n.children[bit] = &node{}
+		}
+		n = n.children[bit]
+	}
+
+	r.Prefix = prefix
+	n.rng = &r
+}
+
+// Lookup returns the most specific range containing the address.
+func (t *Table) Lookup(addr netip.Addr) (Range, bool) {
+	addr = addr.Unmap()
+	n := &t.v6
+	if addr.Is4() {
+		n = &t.v4
+	}
+
+	var match *Range
+	bits := addr.AsSlice()
+	for i := 0; n != nil; i++ {
+		if n.rng != nil {
+			match = n.rng
+		}
+		if i == len(bits)*8 {
+			break
+		}
+		n = n.children[bits[i/8]>>(7-i%8)&1]
+	}
+
+	if match == nil {
+		return Range{}, false
+	}
+
+	return *match, true
+}
+
+// LookupIP returns the cloud service provider owning the address, and the region and service it is used
+// for, if known. The provider is types.Unknown if the address isn't in any range.
+func (t *Table) LookupIP(addr netip.Addr) (provider types.ProviderId, region string, service string) {
+	r, ok := t.Lookup(addr)
+	if !ok {
+		return types.Unknown, "", ""
+	}
+
+	return r.Provider, r.Region, r.Service
+}
+
+// Load returns a table of the ranges in the range files of fsys (aws.json, gcp.json, azure.json,
+// oracle.json and digitalocean.csv, in the formats published by each provider). Missing files are skipped.
+func Load(fsys fs.FS) (*Table, error) {
+	t := &Table{}
+	for _, source := range sources {
+		f, err := fsys.Open(source.name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ranges, err := source.parse(f)
+		closeErr := f.Close()
+		if err = errors.Join(err, closeErr); err != nil {
+			return nil, fmt.Errorf("%s: %w", source.name, err)
+		}
+
+		for _, r := range ranges {
+			t.Insert(r)
+		}
+	}
+
+	return t, nil
+}
+
+// LoadDir returns a table of the ranges in the range files of the directory. See Load.
+func LoadDir(dir string) (*Table, error) {
+	return Load(os.DirFS(dir))
+}
+
+var (
+	defaultTable atomic.Pointer[Table]
+
+	// loadEmbedded loads the embedded snapshots, which are known to be valid.
+	loadEmbedded = sync.OnceValue(func() *Table {
+		data, err := fs.Sub(embedded, "data")
+		if err != nil {
+			panic(err)
+		}
+
+		t, err := Load(data)
+		if err != nil {
+			panic(err)
+		}
+
+		return t
+	})
+)
+
+// Default returns the table used by LookupIP: the table set with SetDefault, or the embedded snapshots.
+func Default() *Table {
+	if t := defaultTable.Load(); t != nil {
+		return t
+	}
+
+	return loadEmbedded()
+}
+
+// SetDefault replaces the table used by LookupIP, e.g. with one loaded from refreshed range files.
+func SetDefault(t *Table) {
+	defaultTable.Store(t)
+}
+
+// LookupIP returns the cloud service provider owning the address in the default table. See Table.LookupIP.
+func LookupIP(addr netip.Addr) (provider types.ProviderId, region string, service string) {
+	return Default().LookupIP(addr)
+}
@@ -0,0 +1,138 @@
+package iprange
+
+import (
+	"net/netip"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func TestTableLookup(t *testing.T) {
+	var table Table
+	table.Insert(Range{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Provider: types.Aws, Region: "wide"})
+	table.Insert(Range{Prefix: netip.MustParsePrefix("10.1.0.0/16"), Provider: types.Aws, Region: "narrow"})
+	table.Insert(Range{Prefix: netip.MustParsePrefix("10.1.2.3/16"), Provider: types.Gcp, Region: "replaced"})
+	table.Insert(Range{Prefix: netip.MustParsePrefix("192.0.2.1/32"), Provider: types.Oci})
+	table.Insert(Range{Prefix: netip.MustParsePrefix("2001:db8::/32"), Provider: types.Azure, Region: "v6"})
+	table.Insert(Range{Prefix: netip.MustParsePrefix("::/0"), Provider: types.DigitalOcean, Region: "default"})
+
+	tests := []struct {
+		name             string
+		addr             string
+		expectedOk       bool
+		expectedProvider types.ProviderId
+		expectedRegion   string
+	}{
+		{name: "Outermost prefix", addr: "10.200.0.1", expectedOk: true, expectedProvider: types.Aws, expectedRegion: "wide"},
+		{name: "Longest prefix", addr: "10.1.255.255", expectedOk: true, expectedProvider: types.Gcp, expectedRegion: "replaced"},
+		{name: "Host prefix", addr: "192.0.2.1", expectedOk: true, expectedProvider: types.Oci},
+		{name: "Next to host prefix", addr: "192.0.2.2"},
+		{name: "No prefix", addr: "11.0.0.1"},
+		{name: "IPv4-mapped IPv6 address", addr: "::ffff:10.1.0.1", expectedOk: true, expectedProvider: types.Gcp, expectedRegion: "replaced"},
+		{name: "IPv6 prefix", addr: "2001:db8:1::1", expectedOk: true, expectedProvider: types.Azure, expectedRegion: "v6"},
+		{name: "IPv6 default route", addr: "2001:db9::1", expectedOk: true, expectedProvider: types.DigitalOcean, expectedRegion: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := table.Lookup(netip.MustParseAddr(tt.addr))
+			if ok != tt.expectedOk {
+				t.Fatalf("Lookup(%s) found = %v; want %v", tt.addr, ok, tt.expectedOk)
+			}
+			if r.Provider != tt.expectedProvider || r.Region != tt.expectedRegion {
+				t.Errorf("Lookup(%s) = %s in %q; want %s in %q", tt.addr, r.Provider, r.Region, tt.expectedProvider, tt.expectedRegion)
+			}
+		})
+	}
+}
+
+func TestTableLookupIP(t *testing.T) {
+	var table Table
+	if provider, region, service := table.LookupIP(netip.MustParseAddr("10.0.0.1")); provider != types.Unknown || region != "" || service != "" {
+		t.Errorf("LookupIP() on an empty table = %s, %q, %q; want %s", provider, region, service, types.Unknown)
+	}
+	if provider, _, _ := table.LookupIP(netip.Addr{}); provider != types.Unknown {
+		t.Errorf("LookupIP() of the zero address = %s; want %s", provider, types.Unknown)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	table, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	tests := []struct {
+		addr             string
+		expectedProvider types.ProviderId
+		expectedRegion   string
+		expectedService  string
+	}{
+		{addr: "3.5.141.1", expectedProvider: types.Aws, expectedRegion: "ap-northeast-2", expectedService: "S3"},
+		{addr: "3.1.1.1", expectedProvider: types.Aws, expectedRegion: "GLOBAL", expectedService: "AMAZON"},
+		{addr: "2600:1f18::1", expectedProvider: types.Aws, expectedRegion: "us-east-1", expectedService: "EC2"},
+		{addr: "34.81.0.1", expectedProvider: types.Gcp, expectedRegion: "asia-east1", expectedService: "Google Cloud"},
+		{addr: "20.38.65.1", expectedProvider: types.Azure, expectedRegion: "westeurope", expectedService: "AzureStorage"},
+		{addr: "20.38.1.1", expectedProvider: types.Azure},
+		{addr: "129.146.1.1", expectedProvider: types.Oci, expectedRegion: "us-phoenix-1", expectedService: "OCI,OSN"},
+		{addr: "104.131.10.10", expectedProvider: types.DigitalOcean, expectedRegion: "US-NY"},
+		{addr: "8.8.8.8", expectedProvider: types.Unknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			provider, region, service := table.LookupIP(netip.MustParseAddr(tt.addr))
+			if provider != tt.expectedProvider || region != tt.expectedRegion || service != tt.expectedService {
+				t.Errorf("LookupIP(%s) = %s, %q, %q; want %s, %q, %q", tt.addr, provider, region, service, tt.expectedProvider, tt.expectedRegion, tt.expectedService)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(fstest.MapFS{}); err != nil {
+		t.Errorf("Load() without range files error = %v; want nil", err)
+	}
+	if _, err := Load(fstest.MapFS{"gcp.json": {Data: []byte("{")}}); err == nil {
+		t.Error("Load() with an invalid range file error = nil; want error")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	t.Cleanup(func() { SetDefault(nil) })
+
+	if Default() == nil {
+		t.Fatal("Default() without a table set = nil; want the embedded table")
+	}
+
+	var table Table
+	table.Insert(Range{Prefix: netip.MustParsePrefix("198.51.100.0/24"), Provider: types.Gcp, Region: "us-central1", Service: "Google Cloud"})
+	SetDefault(&table)
+
+	if provider, region, service := LookupIP(netip.MustParseAddr("198.51.100.7")); provider != types.Gcp || region != "us-central1" || service != "Google Cloud" {
+		t.Errorf("LookupIP() = %s, %q, %q; want the range of the table set with SetDefault()", provider, region, service)
+	}
+}
+
+func TestEmbeddedSnapshots(t *testing.T) {
+	for _, source := range sources {
+		t.Run(source.name, func(t *testing.T) {
+			f, err := embedded.Open("data/" + source.name)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+
+			ranges, err := source.parse(f)
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if len(ranges) == 0 {
+				t.Errorf("embedded %s holds no ranges; refresh it with go generate ./iprange", source.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package iprange
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// awsUmbrellaService is the service of every AWS range. Ranges also used by a specific service are listed again
+// with that service.
+const awsUmbrellaService = "AMAZON"
+
+// ParseAWS parses the AWS ip-ranges.json format (https://ip-ranges.amazonaws.com/ip-ranges.json).
+// When a prefix is listed for several services, the most specific one is kept.
+func ParseAWS(r io.Reader) ([]Range, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPPrefix string `json:"ip_prefix"`
+			Region   string `json:"region"`
+			Service  string `json:"service"`
+		} `json:"prefixes"`
+		IPv6Prefixes []struct {
+			IPv6Prefix string `json:"ipv6_prefix"`
+			Region     string `json:"region"`
+			Service    string `json:"service"`
+		} `json:"ipv6_prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	ranges := newRangeSet(func(existing Range, r Range) bool {
+		return existing.Service == awsUmbrellaService && r.Service != awsUmbrellaService
+	})
+	for _, p := range doc.Prefixes {
+		if err := ranges.add(p.IPPrefix, types.Aws, p.Region, p.Service); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range doc.IPv6Prefixes {
+		if err := ranges.add(p.IPv6Prefix, types.Aws, p.Region, p.Service); err != nil {
+			return nil, err
+		}
+	}
+
+	return ranges.list, nil
+}
+
+// ParseGCP parses the Google Cloud cloud.json format (https://www.gstatic.com/ipranges/cloud.json).
+func ParseGCP(r io.Reader) ([]Range, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPv4Prefix string `json:"ipv4Prefix"`
+			IPv6Prefix string `json:"ipv6Prefix"`
+			Service    string `json:"service"`
+			Scope      string `json:"scope"`
+		} `json:"prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	ranges := newRangeSet(nil)
+	for _, p := range doc.Prefixes {
+		prefix := p.IPv4Prefix
+		if prefix == "" {
+			prefix = p.IPv6Prefix
+		}
+
+		if err := ranges.add(prefix, types.Gcp, p.Scope, p.Service); err != nil {
+			return nil, err
+		}
+	}
+
+	return ranges.list, nil
+}
+
+// ParseAzure parses the Azure Service Tags format (ServiceTags_Public_<date>.json, published weekly).
+// Prefixes are listed under several overlapping tags; the one naming both a region and a service is preferred,
+// then the one naming a service, then the one naming a region.
+func ParseAzure(r io.Reader) ([]Range, error) {
+	var doc struct {
+		Values []struct {
+			Properties struct {
+				Region          string   `json:"region"`
+				SystemService   string   `json:"systemService"`
+				AddressPrefixes []string `json:"addressPrefixes"`
+			} `json:"properties"`
+		} `json:"values"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	specificity := func(r Range) int {
+		score := 0
+		if r.Service != "" {
+			score += 2
+		}
+		if r.Region != "" {
+			score++
+		}
+		return score
+	}
+
+	ranges := newRangeSet(func(existing Range, r Range) bool {
+		return specificity(r) > specificity(existing)
+	})
+	for _, v := range doc.Values {
+		for _, prefix := range v.Properties.AddressPrefixes {
+			if err := ranges.add(prefix, types.Azure, v.Properties.Region, v.Properties.SystemService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ranges.list, nil
+}
+
+// ParseOracle parses the Oracle Cloud Infrastructure public_ip_ranges.json format
+// (https://docs.oracle.com/en-us/iaas/tools/public_ip_ranges.json). The tags of a range are reported as its service.
+func ParseOracle(r io.Reader) ([]Range, error) {
+	var doc struct {
+		Regions []struct {
+			Region string `json:"region"`
+			CIDRs  []struct {
+				CIDR string   `json:"cidr"`
+				Tags []string `json:"tags"`
+			} `json:"cidrs"`
+		} `json:"regions"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	ranges := newRangeSet(nil)
+	for _, region := range doc.Regions {
+		for _, c := range region.CIDRs {
+			if err := ranges.add(c.CIDR, types.Oci, region.Region, strings.Join(c.Tags, ",")); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ranges.list, nil
+}
+
+// ParseDigitalOcean parses the DigitalOcean geofeed format (https://digitalocean.com/geo/google.csv), with the
+// prefix, country, region code, city and postal code of each range. The region code is reported as its region.
+func ParseDigitalOcean(r io.Reader) ([]Range, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	ranges := newRangeSet(nil)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 3 {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected at least 3 fields, found %d", line, len(record))
+		}
+
+		if err = ranges.add(record[0], types.DigitalOcean, record[2], ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return ranges.list, nil
+}
+
+// rangeSet collects ranges with unique prefixes. When a prefix is added again, the new range replaces the
+// existing one if prefer reports so.
+type rangeSet struct {
+	list    []Range
+	indexes map[netip.Prefix]int
+	prefer  func(existing Range, r Range) bool
+}
+
+func newRangeSet(prefer func(existing Range, r Range) bool) *rangeSet {
+	if prefer == nil {
+		prefer = func(Range, Range) bool { return false }
+	}
+
+	return &rangeSet{indexes: make(map[netip.Prefix]int), prefer: prefer}
+}
+
+func (s *rangeSet) add(prefix string, provider types.ProviderId, region string, service string) error {
+	p, err := netip.ParsePrefix(strings.TrimSpace(prefix))
+	if err != nil {
+		return err
+	}
+
+	r := Range{Prefix: p.Masked(), Provider: provider, Region: region, Service: service}
+	if i, ok := s.indexes[r.Prefix]; ok {
+		if s.prefer(s.list[i], r) {
+			s.list[i] = r
+		}
+		return nil
+	}
+
+	s.indexes[r.Prefix] = len(s.list)
+	s.list = append(s.list, r)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package iprange
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		parse       func(string) ([]Range, error)
+		content     string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "AWS prefers specific services",
+			parse:    parser(ParseAWS),
+			content:  `{"prefixes":[{"ip_prefix":"3.5.140.0/22","region":"ap-northeast-2","service":"S3"},{"ip_prefix":"3.5.140.0/22","region":"ap-northeast-2","service":"AMAZON"}],"ipv6_prefixes":[{"ipv6_prefix":"2600:1f18::/33","region":"us-east-1","service":"EC2"}]}`,
+			expected: []string{"3.5.140.0/22 aws ap-northeast-2 S3", "2600:1f18::/33 aws us-east-1 EC2"},
+		},
+		{
+			name:     "GCP",
+			parse:    parser(ParseGCP),
+			content:  `{"prefixes":[{"ipv4Prefix":"34.80.0.0/15","service":"Google Cloud","scope":"asia-east1"},{"ipv6Prefix":"2600:1900:4010::/44","service":"Google Cloud","scope":"europe-west1"}]}`,
+			expected: []string{"34.80.0.0/15 gcp asia-east1 Google Cloud", "2600:1900:4010::/44 gcp europe-west1 Google Cloud"},
+		},
+		{
+			name:     "Azure prefers specific tags",
+			parse:    parser(ParseAzure),
+			content:  `{"values":[{"properties":{"region":"westeurope","addressPrefixes":["20.38.64.0/19"]}},{"properties":{"region":"westeurope","systemService":"AzureStorage","addressPrefixes":["20.38.64.0/19"]}},{"properties":{"systemService":"AzureStorage","addressPrefixes":["20.38.64.0/19"]}}]}`,
+			expected: []string{"20.38.64.0/19 azure westeurope AzureStorage"},
+		},
+		{
+			name:     "Oracle",
+			parse:    parser(ParseOracle),
+			content:  `{"regions":[{"region":"us-phoenix-1","cidrs":[{"cidr":"129.146.0.0/21","tags":["OCI","OSN"]}]}]}`,
+			expected: []string{"129.146.0.0/21 oci us-phoenix-1 OCI,OSN"},
+		},
+		{
+			name:     "DigitalOcean",
+			parse:    parser(ParseDigitalOcean),
+			content:  "104.131.0.0/18,US,US-NY,New York,10011\n2604:a880:800::/48,US,US-NY,New York,10011\n",
+			expected: []string{"104.131.0.0/18 digitalocean US-NY ", "2604:a880:800::/48 digitalocean US-NY "},
+		},
+		{
+			name:     "Unmasked prefix",
+			parse:    parser(ParseGCP),
+			content:  `{"prefixes":[{"ipv4Prefix":"34.81.2.3/15","service":"Google Cloud","scope":"asia-east1"}]}`,
+			expected: []string{"34.80.0.0/15 gcp asia-east1 Google Cloud"},
+		},
+		{name: "Invalid JSON", parse: parser(ParseAWS), content: `{"prefixes":`, expectedErr: true},
+		{name: "Invalid prefix", parse: parser(ParseOracle), content: `{"regions":[{"region":"us-phoenix-1","cidrs":[{"cidr":"129.146.0.0"}]}]}`, expectedErr: true},
+		{name: "Missing CSV fields", parse: parser(ParseDigitalOcean), content: "104.131.0.0/18,US\n", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := tt.parse(tt.content)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("Parse() error = %v; want error %v", err, tt.expectedErr)
+			}
+
+			var actual []string
+			for _, r := range ranges {
+				actual = append(actual, strings.Join([]string{r.Prefix.String(), string(r.Provider), r.Region, r.Service}, " "))
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Parse() = %q; want %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func parser(parse func(io.Reader) ([]Range, error)) func(string) ([]Range, error) {
+	return func(content string) ([]Range, error) {
+		return parse(strings.NewReader(content))
+	}
+}
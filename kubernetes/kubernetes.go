@@ -0,0 +1,59 @@
+// Package kubernetes implements detection of the (managed) Kubernetes distribution the host runs in.
+//
+// In-pod signals establish whether the host is a Kubernetes pod at all. The distribution is then
+// identified from Kubernetes-specific attributes in the metadata of the detected cloud service provider.
+// The labels a pod can read from the downward API are its own, not its node's, so they aren't used.
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceHostEnv          = "KUBERNETES_SERVICE_HOST"
+)
+
+// Detect returns the Kubernetes distribution the host runs in, using the detected cloud service provider
+// to choose which metadata attributes to check. Files are read relative to root.
+//
+// It returns types.NoKubernetes outside a Kubernetes pod, and types.Kubernetes inside a pod
+// whose distribution is not recognized.
+func Detect(ctx context.Context, provider types.ProviderId, root string, logger *zap.Logger) types.KubernetesPlatform {
+	if !InPod(root, logger) {
+		return types.NoKubernetes
+	}
+
+	if platform, ok := checkMetadata(ctx, provider, logger); ok {
+		return platform
+	}
+
+	return types.Kubernetes
+}
+
+// InPod reports whether the host is a Kubernetes pod, from local signals only. Files are read relative to root.
+func InPod(root string, logger *zap.Logger) bool {
+	return checkInPod(filepath.Join(root, serviceAccountTokenFile), logger)
+}
+
+func checkInPod(tokenFile string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking Kubernetes service host variable %s and token file %s", serviceHostEnv, tokenFile))
+
+	if _, ok := os.LookupEnv(serviceHostEnv); ok {
+		return true
+	}
+
+	if _, err := os.Stat(tokenFile); err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return true
+}
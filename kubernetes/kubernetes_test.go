@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestCheckInPod(t *testing.T) {
+	tokenFile := createTempFile(t, "eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl")
+	defer func(name string) {
+		err := os.Remove(name)
+		if err != nil {
+			t.Fatalf("Failed to remove temp file: %v", err)
+		}
+	}(tokenFile)
+
+	logger := zap.NewNop()
+	missing := filepath.Join(t.TempDir(), "token")
+
+	if !checkInPod(tokenFile, logger) {
+		t.Error("Expected checkInPod to return true with a service account token")
+	}
+
+	if checkInPod(missing, logger) {
+		t.Error("Expected checkInPod to return false without a service account token")
+	}
+
+	t.Setenv(serviceHostEnv, "10.96.0.1")
+	if !checkInPod(missing, logger) {
+		t.Errorf("Expected checkInPod to return true with %s set", serviceHostEnv)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := testutil.CreateRoot(t, map[string]string{
+		serviceAccountTokenFile: "eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl",
+		// Downward API labels are the pod's own, which anyone deploying the pod can set.
+		"/etc/podinfo/labels": "app=\"web\"\neks.amazonaws.com/nodegroup=\"default\"\n",
+	})
+
+	logger := zap.NewNop()
+	if platform := Detect(context.Background(), types.Unknown, root, logger); platform != types.Kubernetes {
+		t.Errorf("Detect() = %v; want %v", platform, types.Kubernetes)
+	}
+}
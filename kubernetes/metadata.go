@@ -0,0 +1,203 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	awsTokenURL           string = "http://169.254.169.254/latest/api/token"
+	awsTagsURL            string = "http://169.254.169.254/latest/meta-data/tags/instance"
+	gcpClusterNameURL     string = "http://metadata.google.internal/computeMetadata/v1/instance/attributes/cluster-name"
+	azureResourceGroupURL string = "http://169.254.169.254/metadata/instance/compute/resourceGroupName?api-version=2021-02-01&format=text"
+	ociMetadataURL        string = "http://169.254.169.254/opc/v2/instance/metadata/"
+	doTagsURL             string = "http://169.254.169.254/metadata/v1/tags/"
+	akamaiTokenURL        string = "http://169.254.169.254/v1/token"
+	akamaiInstanceURL     string = "http://169.254.169.254/v1/instance"
+)
+
+// eksClusterTags are the instance tags set on the nodes of EKS managed and self-managed node groups.
+var eksClusterTags = []string{"eks:cluster-name", "aws:eks:cluster-name"}
+
+// lkeLabelPattern matches the labels of the Linodes of LKE node pools, lke<cluster ID>-<pool ID>-<suffix>.
+var lkeLabelPattern = regexp.MustCompile(`^lke\d+-\d+-[0-9a-f]+$`)
+
+type ociMetadataResponse struct {
+	OkeTm string `json:"oke_tm"`
+}
+
+type akamaiInstanceResponse struct {
+	Label string `json:"label"`
+}
+
+// checkMetadata checks the Kubernetes-specific metadata attributes of the given cloud service provider.
+func checkMetadata(ctx context.Context, provider types.ProviderId, logger *zap.Logger) (types.KubernetesPlatform, bool) {
+	switch provider {
+	case types.Aws:
+		return types.Eks, checkEksTags(ctx, logger)
+	case types.Gcp:
+		return types.Gke, checkGkeClusterName(ctx, logger)
+	case types.Azure:
+		return types.Aks, checkAksResourceGroup(ctx, logger)
+	case types.Oci:
+		return types.Oke, checkOkeMetadata(ctx, logger)
+	case types.DigitalOcean:
+		return types.Doks, checkDoksTags(ctx, logger)
+	case types.Akamai:
+		return types.Lke, checkLkeLabel(ctx, logger)
+	default:
+		return types.NoKubernetes, false
+	}
+}
+
+func checkEksTags(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s instance tags using url %s", types.Eks, awsTagsURL))
+
+	token, err := fetch(ctx, http.MethodPut, awsTokenURL, http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"60"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	tags, err := fetch(ctx, http.MethodGet, awsTagsURL, http.Header{"X-Aws-Ec2-Metadata-Token": {string(token)}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	for _, tag := range strings.Fields(string(tags)) {
+		if slices.Contains(eksClusterTags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkGkeClusterName(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s cluster name using url %s", types.Gke, gcpClusterNameURL))
+
+	name, err := fetch(ctx, http.MethodGet, gcpClusterNameURL, http.Header{"Metadata-Flavor": {"Google"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(name)) != ""
+}
+
+// checkAksResourceGroup checks for the node resource group AKS creates for every cluster,
+// which is named MC_<resource group>_<cluster>_<region> unless overridden.
+func checkAksResourceGroup(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s resource group using url %s", types.Aks, azureResourceGroupURL))
+
+	group, err := fetch(ctx, http.MethodGet, azureResourceGroupURL, http.Header{"Metadata": {"true"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(string(group))), "MC_")
+}
+
+func checkOkeMetadata(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", types.Oke, ociMetadataURL))
+
+	body, err := fetch(ctx, http.MethodGet, ociMetadataURL, http.Header{"Authorization": {"Bearer Oracle"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	metadata := new(ociMetadataResponse)
+	if decodeErr := json.Unmarshal(body, metadata); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return false
+	}
+
+	return strings.Contains(metadata.OkeTm, "oke")
+}
+
+// checkDoksTags checks for the k8s:<cluster ID> tag DigitalOcean sets on the droplets of DOKS node pools.
+func checkDoksTags(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s droplet tags using url %s", types.Doks, doTagsURL))
+
+	tags, err := fetch(ctx, http.MethodGet, doTagsURL, nil, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	for _, tag := range strings.Fields(string(tags)) {
+		if strings.HasPrefix(tag, "k8s:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkLkeLabel(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s instance label using url %s", types.Lke, akamaiInstanceURL))
+
+	token, err := fetch(ctx, http.MethodPut, akamaiTokenURL, http.Header{"Metadata-Token-Expiry-Seconds": {"60"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	body, err := fetch(ctx, http.MethodGet, akamaiInstanceURL, http.Header{"Metadata-Token": {string(token)}, "Accept": {"application/json"}}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	instance := new(akamaiInstanceResponse)
+	if decodeErr := json.Unmarshal(body, instance); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return false
+	}
+
+	return lkeLabelPattern.MatchString(instance.Label)
+}
+
+func fetch(ctx context.Context, method string, url string, header http.Header, logger *zap.Logger) ([]byte, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func TestCheckMetadata(t *testing.T) {
+	tests := []struct {
+		name             string
+		provider         types.ProviderId
+		setupMocks       func()
+		expectedPlatform types.KubernetesPlatform
+		expectedResult   bool
+	}{
+		{
+			name:     "EKS cluster tag",
+			provider: types.Aws,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodPut, awsTokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder(http.MethodGet, awsTagsURL,
+					func(req *http.Request) (*http.Response, error) {
+						if req.Header.Get("X-aws-ec2-metadata-token") != "test-token" {
+							return httpmock.NewStringResponse(401, ""), nil
+						}
+						return httpmock.NewStringResponse(200, "Name\neks:cluster-name\neks:nodegroup-name"), nil
+					})
+			},
+			expectedPlatform: types.Eks,
+			expectedResult:   true,
+		},
+		{
+			name:     "EC2 instance without EKS tags",
+			provider: types.Aws,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodPut, awsTokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder(http.MethodGet, awsTagsURL, httpmock.NewStringResponder(200, "Name"))
+			},
+			expectedPlatform: types.Eks,
+			expectedResult:   false,
+		},
+		{
+			name:     "GKE cluster name attribute",
+			provider: types.Gcp,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodGet, gcpClusterNameURL, httpmock.NewStringResponder(200, "prod-cluster"))
+			},
+			expectedPlatform: types.Gke,
+			expectedResult:   true,
+		},
+		{
+			name:     "GCE instance without cluster name attribute",
+			provider: types.Gcp,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodGet, gcpClusterNameURL, httpmock.NewStringResponder(404, ""))
+			},
+			expectedPlatform: types.Gke,
+			expectedResult:   false,
+		},
+		{
+			name:     "AKS node resource group",
+			provider: types.Azure,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodGet, azureResourceGroupURL, httpmock.NewStringResponder(200, "MC_prod_aks-prod_westeurope"))
+			},
+			expectedPlatform: types.Aks,
+			expectedResult:   true,
+		},
+		{
+			name:     "OKE node metadata",
+			provider: types.Oci,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodGet, ociMetadataURL, httpmock.NewStringResponder(200, `{"oke_tm": "oke", "oke-cluster-id": "ocid1.cluster.oc1"}`))
+			},
+			expectedPlatform: types.Oke,
+			expectedResult:   true,
+		},
+		{
+			name:     "DOKS droplet tag",
+			provider: types.DigitalOcean,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodGet, doTagsURL, httpmock.NewStringResponder(200, "k8s\nk8s:7c1f0f4e-1d2c-4b5a-9e8f-123456789abc\nk8s:worker\n"))
+			},
+			expectedPlatform: types.Doks,
+			expectedResult:   true,
+		},
+		{
+			name:     "LKE node label",
+			provider: types.Akamai,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodPut, akamaiTokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder(http.MethodGet, akamaiInstanceURL,
+					func(req *http.Request) (*http.Response, error) {
+						if req.Header.Get("Metadata-Token") != "test-token" {
+							return httpmock.NewStringResponse(401, ""), nil
+						}
+						return httpmock.NewStringResponse(200, `{"id": 123, "label": "lke98765-143215-4b0a6e1f2c3d"}`), nil
+					})
+			},
+			expectedPlatform: types.Lke,
+			expectedResult:   true,
+		},
+		{
+			name:     "Linode without LKE label",
+			provider: types.Akamai,
+			setupMocks: func() {
+				httpmock.RegisterResponder(http.MethodPut, akamaiTokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder(http.MethodGet, akamaiInstanceURL, httpmock.NewStringResponder(200, `{"id": 123, "label": "web-1"}`))
+			},
+			expectedPlatform: types.Lke,
+			expectedResult:   false,
+		},
+		{
+			name:             "Provider without metadata attributes",
+			provider:         types.Unknown,
+			setupMocks:       func() {},
+			expectedPlatform: types.NoKubernetes,
+			expectedResult:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			logger := zap.NewNop()
+			platform, result := checkMetadata(context.Background(), tt.provider, logger)
+
+			if result != tt.expectedResult || platform != tt.expectedPlatform {
+				t.Errorf("checkMetadata() = %v, %v; want %v, %v", platform, result, tt.expectedPlatform, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// MACAddressFiles are the files holding the MAC address of each network interface.
+const MACAddressFiles = "/sys/class/net/*/address"
+
+// Vendor is the cloud service provider or hypervisor that assigns the MAC addresses with an OUI.
+type Vendor struct {
+	Provider       types.ProviderId     // Provider is the provider the addresses point to, if any.
+	Virtualization types.Virtualization // Virtualization is the hypervisor the addresses point to, if any.
+	Confidence     types.Confidence     // Confidence is how strongly the addresses identify the provider.
+}
+
+// ouis are the vendors of the organizationally unique identifiers (the first 3 bytes) of MAC addresses.
+// None identifies a provider on its own, so all are low confidence: Microsoft's OUIs are used by its other
+// products as well as Azure, the Compute Engine and Oracle prefixes are locally administered and can be picked by
+// anyone, and the hypervisor OUIs also appear on desktop hypervisors.
+var ouis = map[string]Vendor{
+	"42:01:0a": {Provider: types.Gcp, Confidence: types.LowConfidence},   // Compute Engine, derived from the internal address
+	"00:0d:3a": {Provider: types.Azure, Confidence: types.LowConfidence}, // Microsoft (Azure)
+	"00:22:48": {Provider: types.Azure, Confidence: types.LowConfidence}, // Microsoft (Azure)
+	"7c:1e:52": {Provider: types.Azure, Confidence: types.LowConfidence}, // Microsoft (Azure)
+	"02:00:17": {Provider: types.Oci, Confidence: types.LowConfidence},   // Oracle Cloud Infrastructure VNICs
+
+	"00:15:5d": {Provider: types.Hyperv, Virtualization: types.HyperV, Confidence: types.LowConfidence}, // Microsoft (Hyper-V)
+	"00:05:69": {Provider: types.Vmware, Virtualization: types.VMware, Confidence: types.LowConfidence}, // VMware
+	"00:0c:29": {Provider: types.Vmware, Virtualization: types.VMware, Confidence: types.LowConfidence}, // VMware
+	"00:1c:14": {Provider: types.Vmware, Virtualization: types.VMware, Confidence: types.LowConfidence}, // VMware
+	"00:50:56": {Provider: types.Vmware, Virtualization: types.VMware, Confidence: types.LowConfidence}, // VMware
+	"00:16:3e": {Virtualization: types.Xen, Confidence: types.LowConfidence},                            // XenSource
+	"52:54:00": {Virtualization: types.Kvm, Confidence: types.LowConfidence},                            // QEMU/KVM (libvirt)
+}
+
+// LookupMAC returns the vendor of the MAC address (e.g. 42:01:0a:80:00:02), if it is a cloud service provider
+// or hypervisor.
+func LookupMAC(addr string) (Vendor, bool) {
+	if len(addr) < len("00:00:00") {
+		return Vendor{}, false
+	}
+
+	vendor, ok := ouis[strings.ToLower(addr[:len("00:00:00")])]
+	return vendor, ok
+}
+
+// ReadMACAddresses returns the MAC addresses in the files matching the pattern (see MACAddressFiles), lowercased.
+// Only the interfaces backed by a device are read: the addresses of bridges, veth pairs and other virtual
+// interfaces are picked by the host's own software, e.g. libvirt's on a bare metal KVM host.
+func ReadMACAddresses(pattern string) ([]string, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, file := range files {
+		if _, statErr := os.Stat(filepath.Join(filepath.Dir(file), "device")); statErr != nil {
+			if !errors.Is(statErr, fs.ErrNotExist) {
+				err = errors.Join(err, statErr)
+			}
+			continue
+		}
+
+		content, readErr := os.ReadFile(file)
+		if readErr != nil {
+			err = errors.Join(err, readErr)
+			continue
+		}
+
+		if addr := strings.ToLower(strings.TrimSpace(string(content))); addr != "" && addr != "00:00:00:00:00:00" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	return addrs, err
+}
+
+// MatchMAC returns the strongest vendor entry of the provider matching one of the MAC addresses.
+func MatchMAC(id types.ProviderId, addrs []string) (Vendor, bool) {
+	var best Vendor
+	found := false
+	for _, addr := range addrs {
+		if vendor, ok := LookupMAC(addr); ok && vendor.Provider == id && (!found || vendor.Confidence > best.Confidence) {
+			best, found = vendor, true
+		}
+	}
+
+	return best, found
+}
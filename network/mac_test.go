@@ -0,0 +1,82 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// createInterface creates the sysfs directory of a network interface with the given MAC address, backed by
+// a device unless virtual is set, and returns the pattern matching the address files.
+func createInterface(t *testing.T, dir string, name string, address string, virtual bool) string {
+	iface := filepath.Join(dir, name)
+	if err := os.MkdirAll(iface, 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if !virtual {
+		if err := os.Mkdir(filepath.Join(iface, "device"), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(iface, "address"), []byte(address+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	return filepath.Join(dir, "*", "address")
+}
+
+func TestLookupMAC(t *testing.T) {
+	tests := []struct {
+		addr           string
+		expectedVendor Vendor
+		expectedResult bool
+	}{
+		{addr: "42:01:0a:80:00:02", expectedVendor: Vendor{Provider: types.Gcp, Confidence: types.LowConfidence}, expectedResult: true},
+		{addr: "00:0D:3A:12:34:56", expectedVendor: Vendor{Provider: types.Azure, Confidence: types.LowConfidence}, expectedResult: true},
+		{addr: "00:50:56:aa:bb:cc", expectedVendor: Vendor{Provider: types.Vmware, Virtualization: types.VMware, Confidence: types.LowConfidence}, expectedResult: true},
+		{addr: "52:54:00:12:34:56", expectedVendor: Vendor{Virtualization: types.Kvm, Confidence: types.LowConfidence}, expectedResult: true},
+		{addr: "02:42:ac:11:00:02", expectedResult: false},
+		{addr: "00:0d", expectedResult: false},
+	}
+
+	for _, tt := range tests {
+		vendor, ok := LookupMAC(tt.addr)
+		if vendor != tt.expectedVendor || ok != tt.expectedResult {
+			t.Errorf("LookupMAC(%q) = %+v, %v; want %+v, %v", tt.addr, vendor, ok, tt.expectedVendor, tt.expectedResult)
+		}
+	}
+}
+
+func TestReadMACAddresses(t *testing.T) {
+	dir := t.TempDir()
+	createInterface(t, dir, "lo", "00:00:00:00:00:00", true)
+	createInterface(t, dir, "virbr0", "52:54:00:aa:bb:cc", true)
+	createInterface(t, dir, "eth0", "42:01:0A:80:00:02", false)
+	pattern := createInterface(t, dir, "eth1", "00:00:00:00:00:00", false)
+
+	addrs, err := ReadMACAddresses(pattern)
+	if err != nil {
+		t.Fatalf("ReadMACAddresses() error = %v", err)
+	}
+
+	if expected := []string{"42:01:0a:80:00:02"}; !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("ReadMACAddresses() = %q; want %q", addrs, expected)
+	}
+}
+
+func TestMatchMAC(t *testing.T) {
+	addrs := []string{"52:54:00:12:34:56", "00:0c:29:12:34:56", "42:01:0a:80:00:02"}
+
+	if vendor, ok := MatchMAC(types.Gcp, addrs); !ok || vendor.Confidence != types.LowConfidence {
+		t.Errorf("MatchMAC(gcp) = %+v, %v; want a low confidence match", vendor, ok)
+	}
+	if vendor, ok := MatchMAC(types.Vmware, addrs); !ok || vendor.Confidence != types.LowConfidence {
+		t.Errorf("MatchMAC(vmware) = %+v, %v; want a low confidence match", vendor, ok)
+	}
+	if vendor, ok := MatchMAC(types.Azure, addrs); ok {
+		t.Errorf("MatchMAC(azure) = %+v; want no match", vendor)
+	}
+}
@@ -0,0 +1,127 @@
+// Package network reads the host's network configuration, for the checks of the providers that recognize
+// their networks from it: the DNS search domains and hostname they assign, and the addresses of their ranges.
+//
+// These are hints only, as any host can be configured the same way.
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	ResolvConfFile = "/etc/resolv.conf" // ResolvConfFile is the resolver configuration, listing the DNS search domains.
+	HostnameFile   = "/etc/hostname"    // HostnameFile holds the hostname, possibly fully qualified.
+)
+
+// interfaceAddrs returns the addresses of the host's network interfaces. It is replaced in tests.
+var interfaceAddrs = net.InterfaceAddrs
+
+// ReadSearchDomains returns the DNS search domains listed in the resolver configuration file, lowercased and
+// without trailing dots. The local domain (the domain directive) is listed first.
+func ReadSearchDomains(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var local, search []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "domain":
+			local = normalize(fields[1:2])
+		case "search":
+			// The last search directive overrides the previous ones.
+			search = normalize(fields[1:])
+		}
+	}
+
+	return append(local, search...), nil
+}
+
+// ReadHostname returns the hostname in the hostname file, lowercased and without trailing dot.
+func ReadHostname(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	fields := normalize(strings.Fields(string(content)))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s: no hostname", file)
+	}
+
+	return fields[0], nil
+}
+
+func normalize(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, strings.TrimSuffix(strings.ToLower(name), "."))
+	}
+
+	return normalized
+}
+
+// InDomain reports whether the name is the domain or one of its subdomains.
+func InDomain(name string, domain string) bool {
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// InterfaceAddrs returns the addresses of the host's network interfaces. Unlike files, interfaces can't be
+// read under a root other than "/", so no addresses are returned then.
+func InterfaceAddrs(root string, logger *zap.Logger) []netip.Addr {
+	if filepath.Clean(root) != "/" {
+		logger.Debug(fmt.Sprintf("Skipping interface addresses, root %s is not /", root))
+		return nil
+	}
+
+	logger.Debug("Reading interface addresses")
+
+	ifaceAddrs, err := interfaceAddrs()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading interface addresses: %s", err))
+		return nil
+	}
+
+	var addrs []netip.Addr
+	for _, a := range ifaceAddrs {
+		prefix, err := netip.ParsePrefix(a.String())
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, prefix.Addr().Unmap())
+	}
+
+	return addrs
+}
+
+// OwnedAddr returns the first public address the provider's published ranges contain (see package iprange).
+// Most providers translate public addresses, but some assign them to the interfaces, as most do for IPv6.
+func OwnedAddr(id types.ProviderId, addrs []netip.Addr) (netip.Addr, bool) {
+	for _, addr := range addrs {
+		if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+			continue
+		}
+
+		if provider, _, _ := iprange.LookupIP(addr); provider == id {
+			return addr, true
+		}
+	}
+
+	return netip.Addr{}, false
+}
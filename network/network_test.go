@@ -0,0 +1,144 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	return file
+}
+
+func TestReadSearchDomains(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileContent string
+		expected    []string
+	}{
+		{
+			name:        "Search domains",
+			fileContent: "# Generated by cloud-init\nnameserver 169.254.169.254\nsearch us-central1-a.c.my-project.internal. c.my-project.internal. google.internal.\n",
+			expected:    []string{"us-central1-a.c.my-project.internal", "c.my-project.internal", "google.internal"},
+		},
+		{
+			name:        "Local domain and last search directive",
+			fileContent: "domain EC2.Internal\nsearch example.com\nsearch reddog.microsoft.com\n",
+			expected:    []string{"ec2.internal", "reddog.microsoft.com"},
+		},
+		{
+			name:        "No search domains",
+			fileContent: "nameserver 127.0.0.53\noptions edns0 trust-ad\n",
+			expected:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domains, err := ReadSearchDomains(createTempFile(t, tt.fileContent))
+			if err != nil {
+				t.Fatalf("ReadSearchDomains() error = %v", err)
+			}
+			if !reflect.DeepEqual(domains, tt.expected) {
+				t.Errorf("ReadSearchDomains() = %q; want %q", domains, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ReadSearchDomains(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ReadSearchDomains() of a missing file error = nil; want error")
+	}
+}
+
+func TestReadHostname(t *testing.T) {
+	hostname, err := ReadHostname(createTempFile(t, "IP-172-31-5-10.ec2.internal.\n"))
+	if err != nil || hostname != "ip-172-31-5-10.ec2.internal" {
+		t.Errorf("ReadHostname() = %q, %v; want %q", hostname, err, "ip-172-31-5-10.ec2.internal")
+	}
+
+	if _, err = ReadHostname(createTempFile(t, "\n")); err == nil {
+		t.Error("ReadHostname() of an empty file error = nil; want error")
+	}
+}
+
+func TestInDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		domain   string
+		expected bool
+	}{
+		{name: "ec2.internal", domain: "ec2.internal", expected: true},
+		{name: "ip-10-0-0-1.ec2.internal", domain: "ec2.internal", expected: true},
+		{name: "notec2.internal", domain: "ec2.internal", expected: false},
+		{name: "internal", domain: "ec2.internal", expected: false},
+	}
+
+	for _, tt := range tests {
+		if actual := InDomain(tt.name, tt.domain); actual != tt.expected {
+			t.Errorf("InDomain(%q, %q) = %v; want %v", tt.name, tt.domain, actual, tt.expected)
+		}
+	}
+}
+
+func TestInterfaceAddrs(t *testing.T) {
+	original := interfaceAddrs
+	t.Cleanup(func() { interfaceAddrs = original })
+
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+			&net.IPNet{IP: net.ParseIP("172.31.5.10"), Mask: net.CIDRMask(20, 32)},
+			&net.IPNet{IP: net.ParseIP("2600:1f18::1"), Mask: net.CIDRMask(64, 128)},
+		}, nil
+	}
+
+	expected := []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("172.31.5.10"), netip.MustParseAddr("2600:1f18::1")}
+	if addrs := InterfaceAddrs("/", zap.NewNop()); !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("InterfaceAddrs(/) = %v; want %v", addrs, expected)
+	}
+
+	if addrs := InterfaceAddrs(t.TempDir(), zap.NewNop()); addrs != nil {
+		t.Errorf("InterfaceAddrs() under another root = %v; want none", addrs)
+	}
+
+	interfaceAddrs = func() ([]net.Addr, error) { return nil, errors.New("no interfaces") }
+	if addrs := InterfaceAddrs("/", zap.NewNop()); addrs != nil {
+		t.Errorf("InterfaceAddrs() with an error = %v; want none", addrs)
+	}
+}
+
+func TestOwnedAddr(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("2600:1f18::/33"), Provider: types.Aws})
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("10.0.0.0/8"), Provider: types.Aws})
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("104.131.0.0/18"), Provider: types.DigitalOcean})
+	iprange.SetDefault(&table)
+
+	addrs := []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("104.131.0.1"), netip.MustParseAddr("2600:1f18::1")}
+
+	if addr, ok := OwnedAddr(types.Aws, addrs); !ok || addr != netip.MustParseAddr("2600:1f18::1") {
+		t.Errorf("OwnedAddr(aws) = %v, %v; want the public address 2600:1f18::1", addr, ok)
+	}
+	if addr, ok := OwnedAddr(types.DigitalOcean, addrs); !ok || addr != netip.MustParseAddr("104.131.0.1") {
+		t.Errorf("OwnedAddr(digitalocean) = %v, %v; want 104.131.0.1", addr, ok)
+	}
+	if addr, ok := OwnedAddr(types.Gcp, addrs); ok {
+		t.Errorf("OwnedAddr(gcp) = %v; want none", addr)
+	}
+}
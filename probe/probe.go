@@ -0,0 +1,235 @@
+// Package probe records the structured result of every check the providers run during detection.
+//
+// Providers wrap each of their checks with File, Request or Run, and send HTTP requests with the
+// client returned by Client. Without a Recorder in the context, the checks run unchanged.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+// maxValueLength is the length values are truncated to.
+const maxValueLength = 80
+
+// maxBodySize is the number of response body bytes kept by the recording client.
+const maxBodySize = 64 << 10
+
+// Outcome is the outcome of a check.
+type Outcome string
+
+const (
+	Pass  Outcome = "pass"  // Pass is used when the check matched the host.
+	Fail  Outcome = "fail"  // Fail is used when the check ran but didn't match the host.
+	Error Outcome = "error" // Error is used when the check couldn't read its source.
+)
+
+// Result is the result of a single check.
+type Result struct {
+	Provider types.ProviderId // Provider is the provider that ran the check.
+	Check    string           // Check is the name of the check, e.g. "imdsv2" or "sys_vendor file".
+	Source   string           // Source is the URL, file or environment variables the check read.
+	Duration time.Duration    // Duration is how long the check took.
+	Value    string           // Value is the (truncated) value read, if any.
+	Outcome  Outcome          // Outcome is the outcome of the check.
+	Err      string           // Err is the error that occurred while reading the source, if any.
+}
+
+// Recorder collects the results of checks. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	results []Result
+}
+
+// Results returns the results recorded so far, in the order the checks completed.
+func (r *Recorder) Results() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Result(nil), r.results...)
+}
+
+func (r *Recorder) record(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, result)
+}
+
+type recorderKey struct{}
+
+type exchangeKey struct{}
+
+// exchange is the last HTTP request and response of a check.
+type exchange struct {
+	mu    sync.Mutex
+	url   string
+	value string
+	err   error
+}
+
+// WithRecorder returns a context that records the results of the checks run with it.
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+func recorderFrom(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
+
+// Run runs a check whose source can't be read back, such as environment variables or file patterns.
+func Run(ctx context.Context, provider types.ProviderId, check string, source string, fn func() bool) bool {
+	recorder := recorderFrom(ctx)
+	if recorder == nil {
+		return fn()
+	}
+
+	start := time.Now()
+	ok := fn()
+
+	recorder.record(Result{
+		Provider: provider,
+		Check:    check,
+		Source:   source,
+		Duration: time.Since(start),
+		Outcome:  outcome(ok, nil),
+	})
+
+	return ok
+}
+
+// File runs a check that reads a file, recording the content of the file as its value.
+func File(ctx context.Context, provider types.ProviderId, check string, file string, fn func() bool) bool {
+	recorder := recorderFrom(ctx)
+	if recorder == nil {
+		return fn()
+	}
+
+	start := time.Now()
+	ok := fn()
+	duration := time.Since(start)
+
+	content, err := os.ReadFile(file)
+	recorder.record(Result{
+		Provider: provider,
+		Check:    check,
+		Source:   file,
+		Duration: duration,
+		Value:    truncate(string(content)),
+		Outcome:  outcome(ok, err),
+		Err:      errString(err),
+	})
+
+	return ok
+}
+
+// Request runs a check that sends HTTP requests with the client returned by Client for the context
+// passed to fn. The status and body of the last response are recorded as its value.
+func Request(ctx context.Context, provider types.ProviderId, check string, url string, fn func(context.Context) bool) bool {
+	recorder := recorderFrom(ctx)
+	if recorder == nil {
+		return fn(ctx)
+	}
+
+	ex := &exchange{url: url}
+	start := time.Now()
+	ok := fn(context.WithValue(ctx, exchangeKey{}, ex))
+	duration := time.Since(start)
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+
+	recorder.record(Result{
+		Provider: provider,
+		Check:    check,
+		Source:   ex.url,
+		Duration: duration,
+		Value:    ex.value,
+		Outcome:  outcome(ok, ex.err),
+		Err:      errString(ex.err),
+	})
+
+	return ok
+}
+
+// Client returns the HTTP client checks send their requests with. Within Request, it records the last
+// request and response of the check.
+func Client(ctx context.Context) *http.Client {
+	ex, ok := ctx.Value(exchangeKey{}).(*exchange)
+	if !ok {
+		return &http.Client{}
+	}
+
+	return &http.Client{Transport: &recordingTransport{exchange: ex}}
+}
+
+// recordingTransport records the requests it sends. The default transport is looked up on every request,
+// so that it can be replaced in tests.
+type recordingTransport struct {
+	exchange *exchange
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+
+	t.exchange.mu.Lock()
+	defer t.exchange.mu.Unlock()
+
+	t.exchange.url = req.URL.String()
+	t.exchange.value = ""
+	t.exchange.err = err
+	if err != nil {
+		return nil, err
+	}
+
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	closeErr := resp.Body.Close()
+	if readErr == nil {
+		readErr = closeErr
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.exchange.value = truncate(fmt.Sprintf("%d %s", resp.StatusCode, body))
+	t.exchange.err = readErr
+
+	return resp, readErr
+}
+
+func outcome(ok bool, err error) Outcome {
+	switch {
+	case ok:
+		return Pass
+	case err != nil:
+		return Error
+	default:
+		return Fail
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// truncate returns the value on a single line, truncated to maxValueLength characters.
+func truncate(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > maxValueLength {
+		return string(runes[:maxValueLength-3]) + "..."
+	}
+
+	return value
+}
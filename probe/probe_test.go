@@ -0,0 +1,241 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const testURL = "http://169.254.169.254/latest/meta-data/"
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestWithoutRecorder(t *testing.T) {
+	ctx := context.Background()
+
+	if !Run(ctx, types.Aws, "environment", "AWS_EXECUTION_ENV", func() bool { return true }) {
+		t.Errorf("Run() = false; want true")
+	}
+
+	if File(ctx, types.Aws, "sys_vendor file", "/nonexistent", func() bool { return false }) {
+		t.Errorf("File() = true; want false")
+	}
+
+	if !Request(ctx, types.Aws, "imdsv1", testURL, func(ctx context.Context) bool {
+		_, ok := Client(ctx).Transport.(*recordingTransport)
+		return !ok
+	}) {
+		t.Errorf("Client() within Request() without a recorder returned a recording client")
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name            string
+		ok              bool
+		expectedOutcome Outcome
+	}{
+		{name: "Passing check", ok: true, expectedOutcome: Pass},
+		{name: "Failing check", ok: false, expectedOutcome: Fail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &Recorder{}
+			ctx := WithRecorder(context.Background(), recorder)
+
+			if ok := Run(ctx, types.Gcp, "environment", "K_SERVICE", func() bool { return tt.ok }); ok != tt.ok {
+				t.Errorf("Run() = %v; want %v", ok, tt.ok)
+			}
+
+			results := recorder.Results()
+			if len(results) != 1 {
+				t.Fatalf("Results() = %v; want a single result", results)
+			}
+
+			result := results[0]
+			if result.Provider != types.Gcp || result.Check != "environment" || result.Source != "K_SERVICE" {
+				t.Errorf("Results()[0] = %+v; want the gcp environment check", result)
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("Results()[0].Outcome = %v; want %v", result.Outcome, tt.expectedOutcome)
+			}
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         *string
+		ok              bool
+		expectedValue   string
+		expectedOutcome Outcome
+	}{
+		{
+			name:            "Passing check",
+			content:         func() *string { s := "Amazon EC2\n"; return &s }(),
+			ok:              true,
+			expectedValue:   "Amazon EC2",
+			expectedOutcome: Pass,
+		},
+		{
+			name:            "Failing check",
+			content:         func() *string { s := "QEMU\n"; return &s }(),
+			ok:              false,
+			expectedValue:   "QEMU",
+			expectedOutcome: Fail,
+		},
+		{
+			name:            "Missing file",
+			content:         nil,
+			ok:              false,
+			expectedOutcome: Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "sys_vendor")
+			if tt.content != nil {
+				file = createTempFile(t, *tt.content)
+				defer os.Remove(file)
+			}
+
+			recorder := &Recorder{}
+			ctx := WithRecorder(context.Background(), recorder)
+
+			if ok := File(ctx, types.Aws, "sys_vendor file", file, func() bool { return tt.ok }); ok != tt.ok {
+				t.Errorf("File() = %v; want %v", ok, tt.ok)
+			}
+
+			result := recorder.Results()[0]
+			if result.Source != file {
+				t.Errorf("Results()[0].Source = %q; want %q", result.Source, file)
+			}
+			if result.Value != tt.expectedValue {
+				t.Errorf("Results()[0].Value = %q; want %q", result.Value, tt.expectedValue)
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("Results()[0].Outcome = %v; want %v", result.Outcome, tt.expectedOutcome)
+			}
+			if (result.Err != "") != (tt.expectedOutcome == Error) {
+				t.Errorf("Results()[0].Err = %q; want an error only for outcome %v", result.Err, Error)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		responder       httpmock.Responder
+		expectedValue   string
+		expectedOutcome Outcome
+	}{
+		{
+			name:            "Passing check",
+			responder:       httpmock.NewStringResponder(200, "ami-id\nhostname\n"),
+			expectedValue:   "200 ami-id hostname",
+			expectedOutcome: Pass,
+		},
+		{
+			name:            "Error response",
+			responder:       httpmock.NewStringResponder(404, "Not Found"),
+			expectedValue:   "404 Not Found",
+			expectedOutcome: Fail,
+		},
+		{
+			name:            "Request error",
+			responder:       httpmock.NewErrorResponder(errors.New("connection refused")),
+			expectedOutcome: Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			httpmock.RegisterResponder("GET", testURL, tt.responder)
+
+			recorder := &Recorder{}
+			ctx := WithRecorder(context.Background(), recorder)
+
+			Request(ctx, types.Aws, "imdsv1", "metadata server", func(ctx context.Context) bool {
+				req, err := http.NewRequestWithContext(ctx, "GET", testURL, nil)
+				if err != nil {
+					t.Fatalf("Failed to create request: %v", err)
+				}
+
+				resp, err := Client(ctx).Do(req)
+				if err != nil {
+					return false
+				}
+				defer resp.Body.Close()
+
+				// The body is still readable after being recorded.
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					t.Errorf("Failed to read response body: %v", err)
+				}
+
+				return resp.StatusCode == http.StatusOK && strings.HasPrefix(string(body), "ami-id")
+			})
+
+			result := recorder.Results()[0]
+			if result.Source != testURL {
+				t.Errorf("Results()[0].Source = %q; want %q", result.Source, testURL)
+			}
+			if result.Value != tt.expectedValue {
+				t.Errorf("Results()[0].Value = %q; want %q", result.Value, tt.expectedValue)
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("Results()[0].Outcome = %v; want %v", result.Outcome, tt.expectedOutcome)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "Short value", value: "Google", expected: "Google"},
+		{name: "Multi-line value", value: "  a\n\tb  \n", expected: "a b"},
+		{name: "Long value", value: strings.Repeat("x", 100), expected: strings.Repeat("x", maxValueLength-3) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.value); got != tt.expected {
+				t.Errorf("truncate(%q) = %q; want %q", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -32,8 +34,8 @@ func (a *Akamai) Identifier() types.ProviderId {
 }
 
 func (a *Akamai) getMetadata(ctx context.Context, logger *zap.Logger) (*metadataResponse, error) {
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "PUT", tokenURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -88,9 +90,19 @@ func (a *Akamai) getMetadata(ctx context.Context, logger *zap.Logger) (*metadata
 	return metadata, nil
 }
 
-func (a *Akamai) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if a.checkMetadataServer(ctx, logger) {
-		ch <- a.Identifier()
+func (a *Akamai) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return a.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
 		return
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -28,7 +29,7 @@ func TestIdentify(t *testing.T) {
 		{
 			name: "Token retrieval succeeds",
 			setupMock: func() {
-				httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 				httpmock.RegisterResponder("GET", metadataURL,
 					httpmock.NewJsonResponderOrPanic(200, metadataResponse{
 						ID:       123,
@@ -46,15 +47,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMock()
 
 			a := &Akamai{}
-			ch := make(chan types.ProviderId)
+			ch := make(chan types.Evidence)
 			logger := zap.NewNop()
 
-			go a.Identify(context.Background(), ch, logger)
+			go a.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedResult {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedResult)
+				if result.Provider != tt.expectedResult {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedResult)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -68,7 +69,7 @@ func TestGetMetadata(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	// Mock token and metadata responses
-	httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+	httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 	httpmock.RegisterResponder("GET", metadataURL,
 		func(req *http.Request) (*http.Response, error) {
 			if req.Header.Get("Metadata-Token") != "test-token" {
@@ -97,7 +98,7 @@ func TestCheckMetadataServer(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+	httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 	httpmock.RegisterResponder("GET", metadataURL,
 		httpmock.NewJsonResponderOrPanic(200, metadataResponse{
 			ID:       123,
@@ -7,10 +7,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -26,14 +29,24 @@ func (a *Alibaba) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (a *Alibaba) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if a.checkMetadataServer(ctx, logger) {
-		ch <- a.Identifier()
+func (a *Alibaba) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
 		return
 	}
 
-	if a.checkVendorFile(vendorFile, logger) {
-		ch <- a.Identifier()
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return a.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return a.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
 		return
 	}
 }
@@ -41,7 +54,7 @@ func (a *Alibaba) Identify(ctx context.Context, ch chan<- types.ProviderId, logg
 func (a *Alibaba) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
@@ -10,6 +10,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -46,11 +47,11 @@ func TestIdentify(t *testing.T) {
 			httpmock.RegisterResponder("GET", metadataURL, tt.responder)
 
 			a := &Alibaba{}
-			ch := make(chan types.ProviderId)
+			ch := make(chan types.Evidence)
 			logger := zap.NewNop()
 
 			// Start Identify in a goroutine
-			go a.Identify(context.Background(), ch, logger)
+			go a.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			// Close the channel after a timeout to simulate the failure case
 			go func() {
@@ -62,11 +63,11 @@ func TestIdentify(t *testing.T) {
 			result, ok := <-ch
 			if !ok {
 				// If the channel is closed without sending a value, handle failure case
-				result = types.Unknown
+				result = types.Evidence{Provider: types.Unknown}
 			}
 
-			if result != tt.expectedResult {
-				t.Errorf("Identify() = %v; want %v", result, tt.expectedResult)
+			if result.Provider != tt.expectedResult {
+				t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedResult)
 			}
 		})
 	}
@@ -3,29 +3,106 @@ package aws
 
 import (
 	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
 const (
 	metadataURL        string = "http://169.254.169.254/latest/dynamic/instance-identity/document"
+	signatureURL       string = "http://169.254.169.254/latest/dynamic/instance-identity/signature"
 	tokenURL           string = "http://169.254.169.254/latest/api/token"
 	productVersionFile        = "/sys/class/dmi/id/product_version"
 	biosVendorFile            = "/sys/class/dmi/id/bios_vendor"
+	boardAssetTagFile         = "/sys/class/dmi/id/board_asset_tag"
+	productUUIDFile           = "/sys/class/dmi/id/product_uuid"
+	hypervisorUUIDFile        = "/sys/hypervisor/uuid"
+	pciVendorFiles            = "/sys/bus/pci/devices/*/vendor"
+	nvmeModelFiles            = "/sys/class/nvme/*/model"
 	identifier                = types.Aws
+
+	// amazonPCIVendor is the PCI vendor ID of Amazon's ENA network adapters and EBS NVMe controllers.
+	amazonPCIVendor = "0x1d0f"
+	// ebsModel is the NVMe model of EBS volumes attached to Nitro instances.
+	ebsModel = "Amazon Elastic Block Store"
+
+	lambdaFunctionEnv    = "AWS_LAMBDA_FUNCTION_NAME"
+	executionEnv         = "AWS_EXECUTION_ENV"
+	ecsContainerMetadata = "ECS_CONTAINER_METADATA_URI_V4"
+	regionEnv            = "AWS_REGION"
 )
 
+// defaultVPC is the address range of the default VPC of every region.
+var defaultVPC = netip.MustParsePrefix("172.31.0.0/16")
+
+// hostnamePattern matches the hostname EC2 derives from the private address of an instance, e.g. ip-172-31-5-10.
+var hostnamePattern = regexp.MustCompile(`^ip-(\d{1,3})-(\d{1,3})-(\d{1,3})-(\d{1,3})$`)
+
 type metadataResponse struct {
 	ImageID    string `json:"imageId"`
 	InstanceID string `json:"instanceId"`
+	Region     string `json:"region"`
+}
+
+// partition returns the AWS partition of the region.
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// taskMetadataResponse is the response of the ECS task metadata endpoint v4.
+type taskMetadataResponse struct {
+	Cluster          string `json:"Cluster"`
+	TaskARN          string `json:"TaskARN"`
+	AvailabilityZone string `json:"AvailabilityZone"`
+	LaunchType       string `json:"LaunchType"`
+}
+
+// region returns the region of the task, taken from its ARN (arn:aws:ecs:<region>:<account>:task/...).
+func (t *taskMetadataResponse) region() string {
+	parts := strings.Split(t.TaskARN, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+
+	return parts[3]
+}
+
+// platform returns the managed runtime the task is launched on.
+func (t *taskMetadataResponse) platform() types.Platform {
+	if strings.EqualFold(t.LaunchType, "FARGATE") {
+		return types.Fargate
+	}
+
+	return types.Ecs
 }
 
 type Aws struct{}
@@ -34,16 +111,18 @@ func (a *Aws) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (a *Aws) getMetadataIMDSv1(ctx context.Context, logger *zap.Logger) (*metadataResponse, error) {
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+// getToken returns an IMDSv2 session token.
+func (a *Aws) getToken(ctx context.Context, logger *zap.Logger) (string, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "PUT", tokenURL, nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", "60")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer func(Body io.ReadCloser) {
 		closeErr := Body.Close()
@@ -53,24 +132,27 @@ func (a *Aws) getMetadataIMDSv1(ctx context.Context, logger *zap.Logger) (*metad
 	}(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+		return "", fmt.Errorf("error response status code: %d", resp.StatusCode)
 	}
 
-	metadata := new(metadataResponse)
-	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
-		return nil, decodeErr
+	token, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
 	}
 
-	return metadata, nil
+	return string(token), nil
 }
 
-func (a *Aws) getMetadataIMDSv2(ctx context.Context, logger *zap.Logger) (*metadataResponse, error) {
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
+// get returns the body of an instance metadata entry, sending the IMDSv2 session token if it isn't empty.
+func (a *Aws) get(ctx context.Context, url string, token string, logger *zap.Logger) ([]byte, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	if token != "" {
+		req.Header.Add("X-aws-ec2-metadata-token", token)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -87,18 +169,171 @@ func (a *Aws) getMetadataIMDSv2(ctx context.Context, logger *zap.Logger) (*metad
 		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
 	}
 
-	token, err := io.ReadAll(resp.Body)
+	return io.ReadAll(resp.Body)
+}
+
+func (a *Aws) getMetadata(ctx context.Context, token string, logger *zap.Logger) (*metadataResponse, error) {
+	body, err := a.get(ctx, metadataURL, token, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := new(metadataResponse)
+	if decodeErr := json.Unmarshal(body, metadata); decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return metadata, nil
+}
+
+func (a *Aws) getMetadataIMDSv1(ctx context.Context, logger *zap.Logger) (*metadataResponse, error) {
+	return a.getMetadata(ctx, "", logger)
+}
+
+func (a *Aws) getMetadataIMDSv2(ctx context.Context, logger *zap.Logger) (*metadataResponse, error) {
+	token, err := a.getToken(ctx, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err = http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	return a.getMetadata(ctx, token, logger)
+}
+
+func (a *Aws) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	// Managed runtimes usually block the metadata service, so they are checked first.
+	var task *taskMetadataResponse
+	if probe.Request(ctx, identifier, "task metadata", ecsContainerMetadata, func(ctx context.Context) (ok bool) {
+		task, ok = a.checkTaskMetadata(ctx, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   a.Identifier(),
+			Confidence: types.HighConfidence,
+			Platform:   task.platform(),
+			Metadata:   types.Metadata{Region: task.region(), Zone: task.AvailabilityZone},
+		}
+		return
+	}
+
+	var platform types.Platform
+	if probe.Run(ctx, identifier, "environment", strings.Join([]string{lambdaFunctionEnv, executionEnv, ecsContainerMetadata}, ", "), func() (ok bool) {
+		platform, ok = a.checkEnvironment(logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   a.Identifier(),
+			Confidence: types.MediumConfidence,
+			Platform:   platform,
+			Metadata:   types.Metadata{Region: os.Getenv(regionEnv)},
+		}
+		return
+	}
+
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	if probe.Request(ctx, identifier, "imdsv2", metadataURL, func(ctx context.Context) bool { return a.checkMetadataServerV2(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if probe.Request(ctx, identifier, "imdsv1", metadataURL, func(ctx context.Context) bool { return a.checkMetadataServerV1(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	// Nitro instances (including bare metal ones) expose the instance ID as the board asset tag.
+	var instanceID string
+	if file := filepath.Join(root, boardAssetTagFile); probe.File(ctx, identifier, "board_asset_tag file", file, func() (ok bool) {
+		instanceID, ok = a.checkBoardAssetTagFile(file, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   a.Identifier(),
+			Confidence: types.MediumConfidence,
+			Metadata:   types.Metadata{InstanceID: instanceID},
+		}
+		return
+	}
+
+	if pattern := filepath.Join(root, pciVendorFiles); probe.Run(ctx, identifier, "pci vendor files", pattern, func() bool { return a.checkPCIVendorFiles(pattern, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if pattern := filepath.Join(root, nvmeModelFiles); probe.Run(ctx, identifier, "nvme model files", pattern, func() bool { return a.checkNVMeModelFiles(pattern, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, productVersionFile); probe.File(ctx, identifier, "product_version file", file, func() bool { return a.checkProductVersionFile(file, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, biosVendorFile); probe.File(ctx, identifier, "bios_vendor file", file, func() bool { return a.checkBiosVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// One in 4096 random UUIDs also starts with ec2, so a match is only reported with low confidence.
+	hypervisorUUID, productUUID := filepath.Join(root, hypervisorUUIDFile), filepath.Join(root, productUUIDFile)
+	if probe.File(ctx, identifier, "hypervisor uuid file", hypervisorUUID, func() bool { return a.checkUUIDFile(hypervisorUUID, logger) }) ||
+		probe.File(ctx, identifier, "product_uuid file", productUUID, func() bool { return a.checkUUIDFile(productUUID, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+
+	// The network configuration is only a hint, as any host can use the same names and addresses. Even an address
+	// in the default VPC together with an EC2 search domain is, as containers on a bridge network drawing from
+	// 172.31.0.0/16 inherit the search domains of their host.
+	var region string
+	addrs := network.InterfaceAddrs(root, logger)
+	if file := filepath.Join(root, network.ResolvConfFile); probe.File(ctx, identifier, "default VPC address and resolv.conf search domains", file, func() (ok bool) {
+		region, ok = a.checkDefaultVPC(file, addrs, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence, Metadata: types.Metadata{Region: region}}
+		return
+	}
+
+	if file := filepath.Join(root, network.ResolvConfFile); probe.File(ctx, identifier, "resolv.conf search domains", file, func() (ok bool) {
+		region, ok = a.checkResolvConfFile(file, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence, Metadata: types.Metadata{Region: region}}
+		return
+	}
+
+	if file := filepath.Join(root, network.HostnameFile); probe.File(ctx, identifier, "hostname file", file, func() (ok bool) {
+		region, ok = a.checkHostnameFile(file, addrs, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence, Metadata: types.Metadata{Region: region}}
+		return
+	}
+
+	if probe.Run(ctx, identifier, "interface addresses", "interfaces", func() bool { return a.checkInterfaceAddresses(addrs, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+}
+
+func (a *Aws) getTaskMetadata(ctx context.Context, endpoint string, logger *zap.Logger) (*taskMetadataResponse, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/task", nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("X-aws-ec2-metadata-token", string(token))
 
-	resp, err = client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -113,34 +348,54 @@ func (a *Aws) getMetadataIMDSv2(ctx context.Context, logger *zap.Logger) (*metad
 		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
 	}
 
-	metadata := new(metadataResponse)
-	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
+	task := new(taskMetadataResponse)
+	if decodeErr := json.NewDecoder(resp.Body).Decode(task); decodeErr != nil {
 		return nil, decodeErr
 	}
 
-	return metadata, nil
+	return task, nil
 }
 
-func (a *Aws) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if a.checkMetadataServerV2(ctx, logger) {
-		ch <- a.Identifier()
-		return
+func (a *Aws) checkTaskMetadata(ctx context.Context, logger *zap.Logger) (*taskMetadataResponse, bool) {
+	endpoint, ok := os.LookupEnv(ecsContainerMetadata)
+	if !ok {
+		logger.Debug(fmt.Sprintf("Skipping %s task metadata check, %s is not set", identifier, ecsContainerMetadata))
+		return nil, false
 	}
 
-	if a.checkMetadataServerV1(ctx, logger) {
-		ch <- a.Identifier()
-		return
+	logger.Debug(fmt.Sprintf("Checking %s task metadata using url %s/task", identifier, endpoint))
+
+	task, err := a.getTaskMetadata(ctx, endpoint, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return nil, false
 	}
 
-	if a.checkProductVersionFile(productVersionFile, logger) {
-		ch <- a.Identifier()
-		return
+	return task, strings.HasPrefix(task.TaskARN, "arn:") && task.LaunchType != ""
+}
+
+func (a *Aws) checkEnvironment(logger *zap.Logger) (types.Platform, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s managed runtime environment variables", identifier))
+
+	if _, ok := os.LookupEnv(lambdaFunctionEnv); ok {
+		return types.Lambda, true
 	}
 
-	if a.checkBiosVendorFile(biosVendorFile, logger) {
-		ch <- a.Identifier()
-		return
+	// AWS_EXECUTION_ENV is e.g. AWS_Lambda_python3.12, AWS_ECS_FARGATE or AWS_ECS_EC2.
+	switch env := os.Getenv(executionEnv); {
+	case strings.HasPrefix(env, "AWS_Lambda_"):
+		return types.Lambda, true
+	case env == "AWS_ECS_FARGATE":
+		return types.Fargate, true
+	case strings.HasPrefix(env, "AWS_ECS_"):
+		return types.Ecs, true
+	}
+
+	if _, ok := os.LookupEnv(ecsContainerMetadata); ok {
+		return types.Ecs, true
 	}
+
+	return types.NoPlatform, false
 }
 
 func (a *Aws) checkMetadataServerV2(ctx context.Context, logger *zap.Logger) bool {
@@ -190,3 +445,242 @@ func (a *Aws) checkBiosVendorFile(file string, logger *zap.Logger) bool {
 
 	return strings.Contains(strings.ToLower(string(content)), "amazon")
 }
+
+func (a *Aws) checkBoardAssetTagFile(file string, logger *zap.Logger) (string, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s board asset tag file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return "", false
+	}
+
+	tag := strings.TrimSpace(string(content))
+	return tag, strings.HasPrefix(tag, "i-")
+}
+
+// checkUUIDFile checks whether a UUID starts with ec2, as the UUIDs of Xen-based EC2 instances
+// (/sys/hypervisor/uuid) and of all EC2 instances (product_uuid, which is only readable by root) do.
+func (a *Aws) checkUUIDFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s uuid file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(string(content))), "ec2")
+}
+
+// dnsRegion returns the region of a name in the internal DNS domains of EC2: ec2.internal in us-east-1,
+// and <region>.compute.internal elsewhere. The region is empty for the compute.internal domain itself.
+func dnsRegion(name string) (string, bool) {
+	switch {
+	case network.InDomain(name, "ec2.internal"):
+		return "us-east-1", true
+	case name == "compute.internal":
+		return "", true
+	case network.InDomain(name, "compute.internal"):
+		labels := strings.Split(strings.TrimSuffix(name, ".compute.internal"), ".")
+		return labels[len(labels)-1], true
+	default:
+		return "", false
+	}
+}
+
+func (a *Aws) checkResolvConfFile(file string, logger *zap.Logger) (string, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s search domains in %s", identifier, file))
+
+	domains, err := network.ReadSearchDomains(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return "", false
+	}
+
+	for _, domain := range domains {
+		if region, ok := dnsRegion(domain); ok {
+			return region, true
+		}
+	}
+
+	return "", false
+}
+
+// checkDefaultVPC checks for an interface address in the default VPC along with an EC2 search domain, which
+// the DHCP options of the default VPC set.
+func (a *Aws) checkDefaultVPC(file string, addrs []netip.Addr, logger *zap.Logger) (string, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s default VPC interface addresses", identifier))
+
+	if !slices.ContainsFunc(addrs, defaultVPC.Contains) {
+		return "", false
+	}
+
+	return a.checkResolvConfFile(file, logger)
+}
+
+// checkHostnameFile checks for an internal DNS name of EC2, or for the hostname EC2 derives from the private
+// address of an instance, if the address is in the default VPC or is one of the host's own.
+func (a *Aws) checkHostnameFile(file string, addrs []netip.Addr, logger *zap.Logger) (string, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s hostname file %s", identifier, file))
+
+	hostname, err := network.ReadHostname(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return "", false
+	}
+
+	if region, ok := dnsRegion(hostname); ok {
+		return region, true
+	}
+
+	match := hostnamePattern.FindStringSubmatch(hostname)
+	if match == nil {
+		return "", false
+	}
+
+	addr, err := netip.ParseAddr(strings.Join(match[1:], "."))
+	if err != nil {
+		return "", false
+	}
+
+	return "", defaultVPC.Contains(addr) || slices.Contains(addrs, addr)
+}
+
+func (a *Aws) checkInterfaceAddresses(addrs []netip.Addr, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s interface addresses", identifier))
+
+	addr, ok := network.OwnedAddr(identifier, addrs)
+	if ok {
+		logger.Debug(fmt.Sprintf("Interface address %s is in a published %s range", addr, identifier))
+	}
+
+	return ok
+}
+
+func (a *Aws) checkPCIVendorFiles(pattern string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s PCI vendor files %s", identifier, pattern))
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+		return false
+	}
+
+	for _, file := range files {
+		content, readErr := os.ReadFile(file)
+		if readErr != nil {
+			logger.Error(fmt.Sprintf("Error reading file: %s", readErr))
+			continue
+		}
+
+		if strings.TrimSpace(string(content)) == amazonPCIVendor {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *Aws) checkNVMeModelFiles(pattern string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s NVMe model files %s", identifier, pattern))
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+		return false
+	}
+
+	for _, file := range files {
+		content, readErr := os.ReadFile(file)
+		if readErr != nil {
+			logger.Error(fmt.Sprintf("Error reading file: %s", readErr))
+			continue
+		}
+
+		if strings.TrimSpace(string(content)) == ebsModel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Attest verifies the signature of the instance identity document against the certificate of the instance's
+// region, read from the trust material attached to the context (see package attest).
+func (a *Aws) Attest(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Verifying %s instance identity document signature using url %s", identifier, signatureURL))
+
+	// IMDSv1 may be disabled, but IMDSv2 may also be blocked (e.g. by a hop limit of 1 in containers).
+	token, err := a.getToken(ctx, logger)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Falling back to IMDSv1: %s", err))
+	}
+
+	document, err := a.get(ctx, metadataURL, token, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	signature, err := a.get(ctx, signatureURL, token, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	metadata := new(metadataResponse)
+	if err = json.Unmarshal(document, metadata); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding instance identity document: %s", err))
+		return false
+	}
+
+	certs, err := a.certificates(attest.Trust(ctx), metadata.Region)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading %s certificates for region %q: %s", identifier, metadata.Region, err))
+		return false
+	}
+
+	if err = a.verifySignature(document, signature, certs); err != nil {
+		logger.Error(fmt.Sprintf("Error verifying instance identity document: %s", err))
+		return false
+	}
+
+	return strings.HasPrefix(metadata.InstanceID, "i-")
+}
+
+// certificates returns the certificates of the region, or of its partition if there are none for the region.
+func (a *Aws) certificates(fsys fs.FS, region string) ([]*x509.Certificate, error) {
+	if region == "" || strings.ContainsAny(region, "/.") {
+		return nil, fmt.Errorf("invalid region %q", region)
+	}
+
+	certs, err := attest.ReadCertificates(fsys, "aws/"+region+".pem")
+	if errors.Is(err, fs.ErrNotExist) {
+		return attest.ReadCertificates(fsys, "aws/"+partition(region)+".pem")
+	}
+
+	return certs, err
+}
+
+// verifySignature verifies the base64-encoded RSA signature of the SHA-256 digest of the document.
+func (a *Aws) verifySignature(document []byte, signature []byte, certs []*x509.Certificate) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(signature)), ""))
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(document)
+	for _, cert := range certs {
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil {
+			return nil
+		}
+	}
+
+	return errors.New("signature doesn't match any certificate")
+}
@@ -2,14 +2,30 @@ package aws
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -29,7 +45,7 @@ func TestIdentify(t *testing.T) {
 		{
 			name: "IMDSv2 succeeds",
 			setupMock: func() {
-				httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+				httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 				httpmock.RegisterResponder("GET", metadataURL,
 					httpmock.NewJsonResponderOrPanic(200, metadataResponse{
 						ImageID:    "ami-123",
@@ -58,15 +74,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMock()
 
 			a := &Aws{}
-			ch := make(chan types.ProviderId)
+			ch := make(chan types.Evidence)
 			logger := zap.NewNop()
 
-			go a.Identify(context.Background(), ch, logger)
+			go a.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedResult {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedResult)
+				if result.Provider != tt.expectedResult {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedResult)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -102,7 +118,7 @@ func TestGetMetadataIMDSv2(t *testing.T) {
 	defer httpmock.DeactivateAndReset()
 
 	// Mock IMDSv2 token and metadata responses
-	httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+	httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 	httpmock.RegisterResponder("GET", metadataURL,
 		func(req *http.Request) (*http.Response, error) {
 			if req.Header.Get("X-aws-ec2-metadata-token") != "test-token" {
@@ -147,7 +163,7 @@ func TestCheckMetadataServerV2(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
 
-	httpmock.RegisterResponder("GET", tokenURL, httpmock.NewStringResponder(200, "test-token"))
+	httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(200, "test-token"))
 	httpmock.RegisterResponder("GET", metadataURL,
 		httpmock.NewJsonResponderOrPanic(200, metadataResponse{
 			ImageID:    "ami-12345678",
@@ -211,3 +227,643 @@ func createTempFile(t *testing.T, content string) string {
 
 	return tmpFile.Name()
 }
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              map[string]string
+		expectedPlatform types.Platform
+		expectedResult   bool
+	}{
+		{
+			name: "Lambda function",
+			env: map[string]string{
+				"AWS_LAMBDA_FUNCTION_NAME": "my-function",
+				"AWS_EXECUTION_ENV":        "AWS_Lambda_python3.12",
+			},
+			expectedPlatform: types.Lambda,
+			expectedResult:   true,
+		},
+		{
+			name: "Fargate task",
+			env: map[string]string{
+				"AWS_EXECUTION_ENV":             "AWS_ECS_FARGATE",
+				"ECS_CONTAINER_METADATA_URI_V4": "http://169.254.170.2/v4/abc",
+			},
+			expectedPlatform: types.Fargate,
+			expectedResult:   true,
+		},
+		{
+			name: "ECS task on EC2",
+			env: map[string]string{
+				"ECS_CONTAINER_METADATA_URI_V4": "http://169.254.170.2/v4/abc",
+			},
+			expectedPlatform: types.Ecs,
+			expectedResult:   true,
+		},
+		{
+			name:             "No managed runtime",
+			env:              map[string]string{},
+			expectedPlatform: types.NoPlatform,
+			expectedResult:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			platform, result := a.checkEnvironment(logger)
+
+			if result != tt.expectedResult || platform != tt.expectedPlatform {
+				t.Errorf("checkEnvironment() = %v, %v; want %v, %v", platform, result, tt.expectedPlatform, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestIdentifyLambda(t *testing.T) {
+	t.Setenv(lambdaFunctionEnv, "my-function")
+	t.Setenv(regionEnv, "eu-west-1")
+
+	a := &Aws{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+
+	a.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+	result := <-ch
+	if result.Provider != identifier || result.Platform != types.Lambda || result.Metadata.Region != "eu-west-1" {
+		t.Errorf("Identify() = %+v; want %v on %v in eu-west-1", result, identifier, types.Lambda)
+	}
+}
+
+func TestCheckTaskMetadata(t *testing.T) {
+	const endpoint = "http://169.254.170.2/v4/a1b2c3d4-e5f6-7890-abcd-ef1234567890"
+
+	tests := []struct {
+		name             string
+		responseStatus   int
+		responseBody     string
+		expectedResult   bool
+		expectedPlatform types.Platform
+		expectedRegion   string
+	}{
+		{
+			name:           "Fargate task",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"Cluster": "arn:aws:ecs:us-west-2:111122223333:cluster/default",
+				"TaskARN": "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+				"AvailabilityZone": "us-west-2d",
+				"LaunchType": "FARGATE"
+			}`,
+			expectedResult:   true,
+			expectedPlatform: types.Fargate,
+			expectedRegion:   "us-west-2",
+		},
+		{
+			name:           "EC2 task",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"Cluster": "default",
+				"TaskARN": "arn:aws:ecs:eu-central-1:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+				"AvailabilityZone": "eu-central-1a",
+				"LaunchType": "EC2"
+			}`,
+			expectedResult:   true,
+			expectedPlatform: types.Ecs,
+			expectedRegion:   "eu-central-1",
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusInternalServerError,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			httpmock.RegisterResponder("GET", endpoint+"/task", httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+			t.Setenv(ecsContainerMetadata, endpoint)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			task, result := a.checkTaskMetadata(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Fatalf("checkTaskMetadata() = %v; want %v", result, tt.expectedResult)
+			}
+
+			if result && (task.platform() != tt.expectedPlatform || task.region() != tt.expectedRegion) {
+				t.Errorf("checkTaskMetadata() = %v in %v; want %v in %v", task.platform(), task.region(), tt.expectedPlatform, tt.expectedRegion)
+			}
+		})
+	}
+}
+
+func TestCheckTaskMetadataWithoutEndpoint(t *testing.T) {
+	a := &Aws{}
+	logger := zap.NewNop()
+	if _, result := a.checkTaskMetadata(context.Background(), logger); result {
+		t.Error("Expected checkTaskMetadata to return false without the metadata endpoint")
+	}
+}
+
+func TestCheckBoardAssetTagFile(t *testing.T) {
+	tests := []struct {
+		name               string
+		fileContent        string
+		expectedInstanceID string
+		expectedResult     bool
+	}{
+		{
+			name:               "EC2 instance ID",
+			fileContent:        "i-0123456789abcdef0\n",
+			expectedInstanceID: "i-0123456789abcdef0",
+			expectedResult:     true,
+		},
+		{
+			name:               "Other asset tag",
+			fileContent:        "Default string\n",
+			expectedInstanceID: "Default string",
+			expectedResult:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			instanceID, result := a.checkBoardAssetTagFile(tmpFile, logger)
+
+			if instanceID != tt.expectedInstanceID || result != tt.expectedResult {
+				t.Errorf("checkBoardAssetTagFile() = %v, %v; want %v, %v", instanceID, result, tt.expectedInstanceID, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckUUIDFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Xen hypervisor UUID",
+			fileContent:    "ec2e1916-9099-7caf-fd21-012345abcdef\n",
+			expectedResult: true,
+		},
+		{
+			name:           "DMI product UUID",
+			fileContent:    "EC2A1B2C-3D4E-5F60-7182-93A4B5C6D7E8\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other UUID",
+			fileContent:    "4c4c4544-0042-3510-8052-b4c04f4e4d32\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			result := a.checkUUIDFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkUUIDFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckResolvConfFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedRegion string
+		expectedResult bool
+	}{
+		{
+			name:           "us-east-1 domain",
+			fileContent:    "nameserver 172.31.0.2\nsearch ec2.internal\n",
+			expectedRegion: "us-east-1",
+			expectedResult: true,
+		},
+		{
+			name:           "Regional domain",
+			fileContent:    "domain eu-west-1.compute.internal\nnameserver 172.31.0.2\n",
+			expectedRegion: "eu-west-1",
+			expectedResult: true,
+		},
+		{
+			name:           "Other domain",
+			fileContent:    "nameserver 10.0.0.2\nsearch corp.example.com\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			region, result := a.checkResolvConfFile(tmpFile, logger)
+
+			if region != tt.expectedRegion || result != tt.expectedResult {
+				t.Errorf("checkResolvConfFile() = %v, %v; want %v, %v", region, result, tt.expectedRegion, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckDefaultVPC(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		addrs          []netip.Addr
+		expectedRegion string
+		expectedResult bool
+	}{
+		{
+			name:           "Default VPC address and us-east-1 domain",
+			fileContent:    "nameserver 172.31.0.2\nsearch ec2.internal\n",
+			addrs:          []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("172.31.5.10")},
+			expectedRegion: "us-east-1",
+			expectedResult: true,
+		},
+		{
+			name:           "Default VPC address and regional domain",
+			fileContent:    "search eu-west-1.compute.internal\n",
+			addrs:          []netip.Addr{netip.MustParseAddr("172.31.40.2")},
+			expectedRegion: "eu-west-1",
+			expectedResult: true,
+		},
+		{
+			name:           "Default VPC address and other domain",
+			fileContent:    "search corp.example.com\n",
+			addrs:          []netip.Addr{netip.MustParseAddr("172.31.5.10")},
+			expectedResult: false,
+		},
+		{
+			name:           "Other address and us-east-1 domain",
+			fileContent:    "search ec2.internal\n",
+			addrs:          []netip.Addr{netip.MustParseAddr("10.0.1.25")},
+			expectedResult: false,
+		},
+		{
+			name:           "No interface addresses",
+			fileContent:    "search ec2.internal\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			region, result := a.checkDefaultVPC(tmpFile, tt.addrs, logger)
+
+			if region != tt.expectedRegion || result != tt.expectedResult {
+				t.Errorf("checkDefaultVPC() = %v, %v; want %v, %v", region, result, tt.expectedRegion, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckHostnameFile(t *testing.T) {
+	addrs := []netip.Addr{netip.MustParseAddr("10.0.1.25")}
+
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedRegion string
+		expectedResult bool
+	}{
+		{
+			name:           "Regional DNS name",
+			fileContent:    "ip-10-0-1-25.ap-south-1.compute.internal\n",
+			expectedRegion: "ap-south-1",
+			expectedResult: true,
+		},
+		{
+			name:           "Address in the default VPC",
+			fileContent:    "ip-172-31-5-10\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Interface address",
+			fileContent:    "ip-10-0-1-25\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other address",
+			fileContent:    "ip-10-0-1-26\n",
+			expectedResult: false,
+		},
+		{
+			name:           "Other hostname",
+			fileContent:    "build-server\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			region, result := a.checkHostnameFile(tmpFile, addrs, logger)
+
+			if region != tt.expectedRegion || result != tt.expectedResult {
+				t.Errorf("checkHostnameFile() = %v, %v; want %v, %v", region, result, tt.expectedRegion, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckInterfaceAddresses(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("2600:1f18::/33"), Provider: types.Aws, Region: "us-east-1"})
+	iprange.SetDefault(&table)
+
+	a := &Aws{}
+	logger := zap.NewNop()
+
+	if !a.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("172.31.5.10"), netip.MustParseAddr("2600:1f18::10")}, logger) {
+		t.Error("checkInterfaceAddresses() with an address in a published range = false; want true")
+	}
+	if a.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("172.31.5.10"), netip.MustParseAddr("2001:db8::10")}, logger) {
+		t.Error("checkInterfaceAddresses() without an address in a published range = true; want false")
+	}
+}
+
+// createDeviceFiles creates one file with the given content per device under a temporary directory,
+// laid out like /sys/class/<class>/<device>/<attribute>, and returns the glob pattern matching them.
+func createDeviceFiles(t *testing.T, attribute string, contents ...string) string {
+	dir := t.TempDir()
+	for i, content := range contents {
+		device := filepath.Join(dir, fmt.Sprintf("device%d", i))
+		if err := os.Mkdir(device, 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(device, attribute), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	return filepath.Join(dir, "*", attribute)
+}
+
+func TestCheckPCIVendorFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		vendors        []string
+		expectedResult bool
+	}{
+		{
+			name:           "ENA adapter",
+			vendors:        []string{"0x8086\n", "0x1d0f\n"},
+			expectedResult: true,
+		},
+		{
+			name:           "No Amazon devices",
+			vendors:        []string{"0x8086\n", "0x1af4\n"},
+			expectedResult: false,
+		},
+		{
+			name:           "No PCI devices",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := createDeviceFiles(t, "vendor", tt.vendors...)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			result := a.checkPCIVendorFiles(pattern, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkPCIVendorFiles() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckNVMeModelFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		models         []string
+		expectedResult bool
+	}{
+		{
+			name:           "EBS volume",
+			models:         []string{"Amazon Elastic Block Store              \n"},
+			expectedResult: true,
+		},
+		{
+			name:           "Other NVMe drive",
+			models:         []string{"Samsung SSD 980 PRO 1TB\n"},
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := createDeviceFiles(t, "model", tt.models...)
+
+			a := &Aws{}
+			logger := zap.NewNop()
+			result := a.checkNVMeModelFiles(pattern, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkNVMeModelFiles() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+// createSigner returns a test RSA key and its PEM-encoded self-signed certificate.
+func createSigner(t *testing.T) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"Amazon Web Services LLC"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, document string) string {
+	digest := sha256.Sum256([]byte(document))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign document: %v", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestAttest(t *testing.T) {
+	key, cert := createSigner(t)
+	otherKey, _ := createSigner(t)
+
+	document := func(region string) string {
+		return fmt.Sprintf(`{"imageId": "ami-12345678", "instanceId": "i-1234567890abcdef0", "region": %q}`, region)
+	}
+
+	tests := []struct {
+		name           string
+		trust          fstest.MapFS
+		document       string
+		signature      string
+		tokenStatus    int
+		expectedResult bool
+	}{
+		{
+			name:           "Valid signature",
+			trust:          fstest.MapFS{"aws/us-east-1.pem": {Data: cert}},
+			document:       document("us-east-1"),
+			signature:      sign(t, key, document("us-east-1")),
+			tokenStatus:    http.StatusOK,
+			expectedResult: true,
+		},
+		{
+			name:           "Valid signature with partition certificate and IMDSv1",
+			trust:          fstest.MapFS{"aws/aws-cn.pem": {Data: cert}},
+			document:       document("cn-north-1"),
+			signature:      sign(t, key, document("cn-north-1")),
+			tokenStatus:    http.StatusForbidden,
+			expectedResult: true,
+		},
+		{
+			name:           "Signature by another key",
+			trust:          fstest.MapFS{"aws/us-east-1.pem": {Data: cert}},
+			document:       document("us-east-1"),
+			signature:      sign(t, otherKey, document("us-east-1")),
+			tokenStatus:    http.StatusOK,
+			expectedResult: false,
+		},
+		{
+			name:           "Tampered document",
+			trust:          fstest.MapFS{"aws/us-east-1.pem": {Data: cert}},
+			document:       document("us-east-1"),
+			signature:      sign(t, key, document("us-west-2")),
+			tokenStatus:    http.StatusOK,
+			expectedResult: false,
+		},
+		{
+			name:           "No certificate for region",
+			trust:          fstest.MapFS{"aws/eu-west-1.pem": {Data: cert}},
+			document:       document("us-east-1"),
+			signature:      sign(t, key, document("us-east-1")),
+			tokenStatus:    http.StatusOK,
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("PUT", tokenURL, httpmock.NewStringResponder(tt.tokenStatus, "test-token"))
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, tt.document))
+			httpmock.RegisterResponder("GET", signatureURL, httpmock.NewStringResponder(200, tt.signature))
+
+			a := &Aws{}
+			ctx := attest.WithTrust(context.Background(), tt.trust)
+
+			if result := a.Attest(ctx, zap.NewNop()); result != tt.expectedResult {
+				t.Errorf("Attest() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestAttestWithoutMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	a := &Aws{}
+	if a.Attest(context.Background(), zap.NewNop()) {
+		t.Errorf("Attest() = true; want false")
+	}
+}
+
+func TestPartition(t *testing.T) {
+	tests := map[string]string{
+		"us-east-1":      "aws",
+		"eu-central-2":   "aws",
+		"cn-northwest-1": "aws-cn",
+		"us-gov-west-1":  "aws-us-gov",
+	}
+
+	for region, expected := range tests {
+		if got := partition(region); got != expected {
+			t.Errorf("partition(%q) = %q; want %q", region, got, expected)
+		}
+	}
+}
@@ -3,24 +3,73 @@ package azure
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"math/big"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
 const (
-	metadataURL string = "http://169.254.169.254/metadata/instance?api-version=2017-12-01"
-	vendorFile         = "/sys/class/dmi/id/sys_vendor"
-	identifier         = types.Azure
+	metadataURL   string = "http://169.254.169.254/metadata/instance?api-version=2017-12-01"
+	attestedURL   string = "http://169.254.169.254/metadata/attested/document?api-version=2020-09-01"
+	wireServerURL        = "http://168.63.129.16/?comp=versions"
+	identifier           = types.Azure
+
+	// ChassisAssetFile is the file holding the chassis asset tag of the host.
+	ChassisAssetFile = "/sys/class/dmi/id/chassis_asset_tag"
+	// chassisAssetTag is the chassis asset tag Azure sets on its virtual machines. Other Hyper-V hosts don't.
+	chassisAssetTag = "7783-7084-3265-9085-8269-3286-77"
+
+	functionsRuntimeEnv = "FUNCTIONS_WORKER_RUNTIME"
+	containerAppEnv     = "CONTAINER_APP_NAME"
+	siteNameEnv         = "WEBSITE_SITE_NAME"
+	instanceIDEnv       = "WEBSITE_INSTANCE_ID"
+	regionEnv           = "REGION_NAME"
+
+	// maxIssuerFetches is the number of intermediates fetched from issuer URLs, more than Azure's chains need.
+	maxIssuerFetches = 3
+	// maxIssuerSize is the maximum size of a certificate fetched from an issuer URL.
+	maxIssuerSize = 64 << 10
 )
 
+// agentFiles are written by the Azure Linux agent (waagent) from the configuration the Azure fabric provisions the
+// virtual machine with, so they are absent on images that merely have the agent installed.
+var agentFiles = []string{
+	"/var/lib/waagent/ovf-env.xml",
+	"/var/lib/waagent/SharedConfig.xml",
+	"/var/lib/waagent/HostingEnvironmentConfig.xml",
+}
+
+// dnsDomains are the domains of the Azure-provided DNS in the public and sovereign clouds, e.g.
+// <id>.bx.internal.cloudapp.net, and of older deployments.
+var dnsDomains = []string{"internal.cloudapp.net", "internal.chinacloudapp.cn", "internal.usgovcloudapp.net", "reddog.microsoft.com"}
+
+type versionsResponse struct {
+	Preferred struct {
+		Version string `xml:"Version"`
+	} `xml:"Preferred"`
+}
+
 type compute struct {
 	VMID string `json:"vmId"`
 }
@@ -29,28 +78,129 @@ type metadataResponse struct {
 	Compute compute `json:"compute"`
 }
 
+// attestedResponse is the response of the attested metadata endpoint.
+type attestedResponse struct {
+	Encoding  string `json:"encoding"`
+	Signature string `json:"signature"`
+}
+
+// attestedDocument is the signed content of the attested metadata document.
+type attestedDocument struct {
+	Nonce string `json:"nonce"`
+	VMID  string `json:"vmId"`
+}
+
+// signingDomains are the domains of the certificates the attested metadata is signed with, in the public
+// and sovereign clouds, e.g. metadata.azure.com or eastus.metadata.azure.com.
+var signingDomains = []string{"metadata.azure.com", "metadata.azure.us", "metadata.azure.cn", "metadata.microsoftazure.de"}
+
 type Azure struct{}
 
 func (a *Azure) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (a *Azure) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if a.checkMetadataServer(ctx, logger) {
-		ch <- a.Identifier()
+func (a *Azure) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	// Managed runtimes do not expose the instance metadata service, so they are checked first.
+	var platform types.Platform
+	var confidence types.Confidence
+	if probe.Run(ctx, identifier, "environment", strings.Join([]string{functionsRuntimeEnv, instanceIDEnv, containerAppEnv, siteNameEnv}, ", "), func() (ok bool) {
+		platform, confidence, ok = a.checkEnvironment(logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   a.Identifier(),
+			Confidence: confidence,
+			Platform:   platform,
+			Metadata:   types.Metadata{Region: os.Getenv(regionEnv)},
+		}
+		return
+	}
+
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return a.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
 		return
 	}
 
-	if a.checkVendorFile(vendorFile, logger) {
-		ch <- a.Identifier()
+	if probe.Request(ctx, identifier, "wireserver", wireServerURL, func(ctx context.Context) bool { return a.checkWireServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.HighConfidence}
 		return
 	}
+
+	if file := filepath.Join(root, ChassisAssetFile); probe.File(ctx, identifier, "chassis_asset_tag file", file, func() bool { return a.CheckChassisAssetFile(file, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	files := make([]string, len(agentFiles))
+	for i, file := range agentFiles {
+		files[i] = filepath.Join(root, file)
+	}
+
+	if probe.Run(ctx, identifier, "agent files", strings.Join(files, ", "), func() bool { return a.checkAgentFiles(files, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if pattern := filepath.Join(root, network.MACAddressFiles); probe.Run(ctx, identifier, "mac address files", pattern, func() (ok bool) {
+		confidence, ok = a.checkMACAddressFiles(pattern, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: confidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// The network configuration is only a hint, as any host can use the same names and addresses.
+	if file := filepath.Join(root, network.ResolvConfFile); probe.File(ctx, identifier, "resolv.conf search domains", file, func() bool { return a.checkResolvConfFile(file, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+
+	addrs := network.InterfaceAddrs(root, logger)
+	if probe.Run(ctx, identifier, "interface addresses", "interfaces", func() bool { return a.checkInterfaceAddresses(addrs, logger) }) {
+		ch <- types.Evidence{Provider: a.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+}
+
+func (a *Azure) checkEnvironment(logger *zap.Logger) (types.Platform, types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s managed runtime environment variables", identifier))
+
+	lookup := func(name string) bool {
+		_, ok := os.LookupEnv(name)
+		return ok
+	}
+
+	// Azure Functions run on App Service and also set WEBSITE_SITE_NAME, so they are checked first. Azure Functions
+	// Core Tools set FUNCTIONS_WORKER_RUNTIME on local runs too, but not the App Service instance ID.
+	switch {
+	case lookup(functionsRuntimeEnv) && lookup(instanceIDEnv):
+		return types.AzureFunctions, types.MediumConfidence, true
+	case lookup(functionsRuntimeEnv):
+		return types.AzureFunctions, types.LowConfidence, true
+	case lookup(containerAppEnv):
+		return types.ContainerApps, types.MediumConfidence, true
+	case lookup(siteNameEnv):
+		return types.AppService, types.MediumConfidence, true
+	}
+
+	return types.NoPlatform, types.NoConfidence, false
 }
 
 func (a *Azure) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
@@ -84,8 +234,49 @@ func (a *Azure) checkMetadataServer(ctx context.Context, logger *zap.Logger) boo
 	return len(metadata.Compute.VMID) > 0
 }
 
-func (a *Azure) checkVendorFile(file string, logger *zap.Logger) bool {
-	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+// checkWireServer checks the WireServer, the Azure fabric endpoint the guest agent talks to.
+// It is reachable from virtual machines that have the instance metadata service blocked.
+func (a *Azure) checkWireServer(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s WireServer using url %s", identifier, wireServerURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", wireServerURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error sending request: %s", err))
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return false
+	}
+
+	versions := new(versionsResponse)
+	if decodeErr := xml.NewDecoder(resp.Body).Decode(versions); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return false
+	}
+
+	return len(versions.Preferred.Version) > 0
+}
+
+// CheckChassisAssetFile reports whether the chassis asset tag is the one Azure sets on its virtual machines.
+//
+// Azure virtual machines run on Hyper-V, so the Hyper-V provider uses this to tell them apart from its own.
+func (a *Azure) CheckChassisAssetFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s chassis asset tag file %s", identifier, file))
 
 	content, err := os.ReadFile(file)
 	if err != nil {
@@ -93,5 +284,258 @@ func (a *Azure) checkVendorFile(file string, logger *zap.Logger) bool {
 		return false
 	}
 
-	return strings.Contains(string(content), "Microsoft Corporation")
+	return strings.TrimSpace(string(content)) == chassisAssetTag
+}
+
+func (a *Azure) checkAgentFiles(files []string, logger *zap.Logger) bool {
+	for _, file := range files {
+		logger.Debug(fmt.Sprintf("Checking %s agent file %s", identifier, file))
+
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *Azure) checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s MAC address files %s", identifier, pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	vendor, ok := network.MatchMAC(identifier, addrs)
+	return vendor.Confidence, ok
+}
+
+func (a *Azure) checkResolvConfFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s search domains in %s", identifier, file))
+
+	domains, err := network.ReadSearchDomains(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	for _, domain := range domains {
+		for _, d := range dnsDomains {
+			if network.InDomain(domain, d) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a *Azure) checkInterfaceAddresses(addrs []netip.Addr, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s interface addresses", identifier))
+
+	addr, ok := network.OwnedAddr(identifier, addrs)
+	if ok {
+		logger.Debug(fmt.Sprintf("Interface address %s is in a published %s range", addr, identifier))
+	}
+
+	return ok
+}
+
+// Attest fetches the attested metadata document with a fresh nonce, and verifies that it is signed by
+// an Azure metadata certificate that chains to the roots read from the trust material attached to the
+// context (see package attest).
+func (a *Azure) Attest(ctx context.Context, logger *zap.Logger) bool {
+	// The nonce is an optional 10-digit string, echoed in the signed document so that it can't be replayed.
+	n, err := rand.Int(rand.Reader, big.NewInt(1e10))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error generating nonce: %s", err))
+		return false
+	}
+	nonce := fmt.Sprintf("%010d", n)
+
+	url := attestedURL + "&nonce=" + nonce
+	logger.Debug(fmt.Sprintf("Verifying %s attested metadata using url %s", identifier, url))
+
+	signature, err := a.getAttestedSignature(ctx, url, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+
+	opts, err := a.verifyOptions(attest.Trust(ctx))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading %s certificates: %s", identifier, err))
+		return false
+	}
+
+	content, signer, err := a.verifySignature(ctx, signature, opts, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error verifying attested metadata: %s", err))
+		return false
+	}
+
+	if !a.isSigningCertificate(signer) {
+		logger.Error(fmt.Sprintf("Attested metadata signed by unexpected certificate %s", signer.Subject))
+		return false
+	}
+
+	document := new(attestedDocument)
+	if err = json.Unmarshal(content, document); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding attested metadata: %s", err))
+		return false
+	}
+
+	if document.Nonce != nonce {
+		logger.Error(fmt.Sprintf("Attested metadata nonce %q doesn't match %q", document.Nonce, nonce))
+		return false
+	}
+
+	return len(document.VMID) > 0
+}
+
+// getAttestedSignature returns the DER-encoded PKCS #7 signature served by the attested metadata endpoint.
+func (a *Azure) getAttestedSignature(ctx context.Context, url string, logger *zap.Logger) ([]byte, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Metadata", "true")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+
+	attested := new(attestedResponse)
+	if err = json.NewDecoder(resp.Body).Decode(attested); err != nil {
+		return nil, err
+	}
+
+	if attested.Encoding != "pkcs7" {
+		return nil, fmt.Errorf("unsupported encoding %q", attested.Encoding)
+	}
+
+	return base64.StdEncoding.DecodeString(attested.Signature)
+}
+
+// verifyOptions returns the options the signing certificate is verified with: the roots in azure/roots.pem,
+// or the system roots if there is no such file, and the intermediates in azure/intermediates.pem, if any.
+func (a *Azure) verifyOptions(fsys fs.FS) (x509.VerifyOptions, error) {
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+
+	roots, err := attest.ReadCertificates(fsys, "azure/roots.pem")
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if opts.Roots, err = x509.SystemCertPool(); err != nil {
+			return opts, err
+		}
+	case err != nil:
+		return opts, err
+	}
+	for _, cert := range roots {
+		opts.Roots.AddCert(cert)
+	}
+
+	intermediates, err := attest.ReadCertificates(fsys, "azure/intermediates.pem")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return opts, err
+	}
+	for _, cert := range intermediates {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	return opts, nil
+}
+
+// verifySignature verifies the PKCS #7 signature. Azure doesn't include the intermediates in the signature,
+// so those missing from opts are fetched from the issuer URL of the certificate that doesn't chain to a root.
+// They are only trusted if they chain to one of the roots themselves.
+func (a *Azure) verifySignature(ctx context.Context, signature []byte, opts x509.VerifyOptions, logger *zap.Logger) ([]byte, *x509.Certificate, error) {
+	for range maxIssuerFetches {
+		content, signer, err := attest.VerifyPKCS7(signature, opts)
+
+		var unknownAuthority x509.UnknownAuthorityError
+		if !errors.As(err, &unknownAuthority) || unknownAuthority.Cert == nil || len(unknownAuthority.Cert.IssuingCertificateURL) == 0 {
+			return content, signer, err
+		}
+
+		issuers, fetchErr := a.getIssuers(ctx, unknownAuthority.Cert, logger)
+		if fetchErr != nil {
+			return nil, nil, errors.Join(err, fetchErr)
+		}
+		for _, cert := range issuers {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+
+	return attest.VerifyPKCS7(signature, opts)
+}
+
+// getIssuers returns the certificates published at the first issuer URL of the certificate, DER or
+// PEM-encoded.
+func (a *Azure) getIssuers(ctx context.Context, cert *x509.Certificate, logger *zap.Logger) ([]*x509.Certificate, error) {
+	url := cert.IssuingCertificateURL[0]
+	logger.Debug(fmt.Sprintf("Fetching issuer of %s using url %s", cert.Subject, url))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxIssuerSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(content); block != nil {
+		content = block.Bytes
+	}
+
+	return x509.ParseCertificates(content)
+}
+
+// isSigningCertificate reports whether the certificate is issued to one of the signing domains.
+func (a *Azure) isSigningCertificate(cert *x509.Certificate) bool {
+	for _, name := range cert.DNSNames {
+		name = strings.TrimPrefix(name, "*.")
+		for _, domain := range signingDomains {
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
@@ -2,14 +2,24 @@ package azure
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/attest/attesttest"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -44,15 +54,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMocks()
 
 			a := &Azure{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go a.Identify(context.Background(), ch, logger)
+			go a.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -106,20 +116,65 @@ func TestCheckMetadataServer(t *testing.T) {
 	}
 }
 
-func TestCheckVendorFile(t *testing.T) {
+func TestCheckWireServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name           string
+		responseBody   string
+		responseStatus int
+		expectedResult bool
+	}{
+		{
+			name:           "Valid versions response",
+			responseBody:   `<?xml version="1.0" encoding="utf-8"?><Versions><Preferred><Version>2015-04-05</Version></Preferred><Supported><Version>2015-04-05</Version><Version>2012-11-30</Version></Supported></Versions>`,
+			responseStatus: http.StatusOK,
+			expectedResult: true,
+		},
+		{
+			name:           "Unrelated response",
+			responseBody:   `<html><body>It works!</body></html>`,
+			responseStatus: http.StatusOK,
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseBody:   "",
+			responseStatus: http.StatusNotFound,
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", wireServerURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			a := &Azure{}
+			logger := zap.NewNop()
+			result := a.checkWireServer(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkWireServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckChassisAssetFile(t *testing.T) {
 	tests := []struct {
 		name           string
 		fileContent    string
 		expectedResult bool
 	}{
 		{
-			name:           "Vendor file contains Microsoft Corporation",
-			fileContent:    "This machine is by Microsoft Corporation",
+			name:           "Azure chassis asset tag",
+			fileContent:    "7783-7084-3265-9085-8269-3286-77\n",
 			expectedResult: true,
 		},
 		{
-			name:           "Vendor file does not contain Microsoft Corporation",
-			fileContent:    "This machine is by Another Vendor",
+			name:           "Hyper-V chassis asset tag",
+			fileContent:    "5471-3318-1037-7021-8715-0437-93\n",
 			expectedResult: false,
 		},
 	}
@@ -136,22 +191,43 @@ func TestCheckVendorFile(t *testing.T) {
 
 			a := &Azure{}
 			logger := zap.NewNop()
-			result := a.checkVendorFile(tmpFile, logger)
+			result := a.CheckChassisAssetFile(tmpFile, logger)
 
 			if result != tt.expectedResult {
-				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+				t.Errorf("CheckChassisAssetFile() = %v; want %v", result, tt.expectedResult)
 			}
 		})
 	}
 }
 
-func TestCheckVendorFile_FileNotFound(t *testing.T) {
+func TestCheckChassisAssetFile_FileNotFound(t *testing.T) {
 	a := &Azure{}
 	logger := zap.NewNop()
-	result := a.checkVendorFile("/path/to/nonexistent/file", logger)
+	result := a.CheckChassisAssetFile("/path/to/nonexistent/file", logger)
 
 	if result {
-		t.Errorf("Expected checkVendorFile() to return false for nonexistent file")
+		t.Errorf("Expected CheckChassisAssetFile() to return false for nonexistent file")
+	}
+}
+
+func TestCheckAgentFiles(t *testing.T) {
+	tmpFile := createTempFile(t, "<Environment />")
+	defer func(name string) {
+		err := os.Remove(name)
+		if err != nil {
+			t.Fatalf("Failed to remove temp file: %v", err)
+		}
+	}(tmpFile)
+
+	a := &Azure{}
+	logger := zap.NewNop()
+
+	if !a.checkAgentFiles([]string{"/path/to/nonexistent/file", tmpFile}, logger) {
+		t.Error("Expected checkAgentFiles() to return true with a provisioned agent file")
+	}
+
+	if a.checkAgentFiles([]string{"/path/to/nonexistent/file"}, logger) {
+		t.Error("Expected checkAgentFiles() to return false without agent files")
 	}
 }
 
@@ -171,3 +247,317 @@ func createTempFile(t *testing.T, content string) string {
 
 	return tmpFile.Name()
 }
+
+func TestCheckResolvConfFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Azure-provided DNS",
+			fileContent:    "search 3gkcaazuqk1ebo1kh0ac0pisoh.bx.internal.cloudapp.net\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Older deployment",
+			fileContent:    "nameserver 168.63.129.16\nsearch reddog.microsoft.com\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other domain",
+			fileContent:    "nameserver 10.0.0.2\nsearch corp.example.com\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			a := &Azure{}
+			logger := zap.NewNop()
+			result := a.checkResolvConfFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkResolvConfFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckInterfaceAddresses(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("20.38.64.0/19"), Provider: identifier})
+	iprange.SetDefault(&table)
+
+	a := &Azure{}
+	logger := zap.NewNop()
+
+	if !a.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("20.38.64.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return true with an address in a published range")
+	}
+
+	if a.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("198.51.100.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return false without an address in a published range")
+	}
+}
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name               string
+		env                map[string]string
+		expectedPlatform   types.Platform
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name: "Azure Functions",
+			env: map[string]string{
+				"FUNCTIONS_WORKER_RUNTIME": "python",
+				"WEBSITE_SITE_NAME":        "my-func",
+				"WEBSITE_INSTANCE_ID":      "a1b2c3",
+			},
+			expectedPlatform:   types.AzureFunctions,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "Azure Functions Core Tools",
+			env: map[string]string{
+				"FUNCTIONS_WORKER_RUNTIME": "python",
+			},
+			expectedPlatform:   types.AzureFunctions,
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "App Service",
+			env: map[string]string{
+				"WEBSITE_SITE_NAME": "my-site",
+			},
+			expectedPlatform:   types.AppService,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "Container Apps",
+			env: map[string]string{
+				"CONTAINER_APP_NAME": "my-app",
+			},
+			expectedPlatform:   types.ContainerApps,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:             "No managed runtime",
+			env:              map[string]string{},
+			expectedPlatform: types.NoPlatform,
+			expectedResult:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			a := &Azure{}
+			logger := zap.NewNop()
+			platform, confidence, result := a.checkEnvironment(logger)
+
+			if result != tt.expectedResult || platform != tt.expectedPlatform || confidence != tt.expectedConfidence {
+				t.Errorf("checkEnvironment() = %v, %v, %v; want %v, %v, %v", platform, confidence, result, tt.expectedPlatform, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
+
+// attestedResponder answers attested metadata requests with a document signed by signer, echoing the
+// nonce of the request unless nonce is set.
+func attestedResponder(t *testing.T, signer *attesttest.Identity, nonce string) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		if nonce == "" {
+			nonce = req.URL.Query().Get("nonce")
+		}
+
+		content := fmt.Sprintf(`{"nonce":%q,"vmId":"02aab8a4-74ef-476e-8182-f6d2ba4166a6","subscriptionId":"8d10da13-8125-4ba9-a717-bf7490507b3d"}`, nonce)
+		signature := base64.StdEncoding.EncodeToString(attesttest.SignPKCS7(t, []byte(content), signer))
+
+		return httpmock.NewJsonResponse(http.StatusOK, attestedResponse{Encoding: "pkcs7", Signature: signature})
+	}
+}
+
+func TestAttest(t *testing.T) {
+	root := attesttest.NewCA(t, "Test Root CA")
+	intermediate := root.Issue(t)
+	otherRoot := attesttest.NewCA(t, "Other Root CA")
+	published := root.Issue(t)
+	published.URL = "http://pki.example.com/intermediate.crt"
+
+	trust := fstest.MapFS{
+		"azure/roots.pem":         {Data: root.PEM()},
+		"azure/intermediates.pem": {Data: intermediate.PEM()},
+	}
+
+	tests := []struct {
+		name           string
+		trust          fstest.MapFS
+		responder      httpmock.Responder
+		issuer         httpmock.Responder
+		expectedResult bool
+	}{
+		{
+			name:           "Valid document",
+			trust:          trust,
+			responder:      attestedResponder(t, intermediate.Issue(t, "metadata.azure.com"), ""),
+			expectedResult: true,
+		},
+		{
+			name:           "Valid document signed by a regional certificate",
+			trust:          trust,
+			responder:      attestedResponder(t, intermediate.Issue(t, "eastus.metadata.azure.com"), ""),
+			expectedResult: true,
+		},
+		{
+			name:           "Replayed document",
+			trust:          trust,
+			responder:      attestedResponder(t, intermediate.Issue(t, "metadata.azure.com"), "0123456789"),
+			expectedResult: false,
+		},
+		{
+			name:           "Document signed for another domain",
+			trust:          trust,
+			responder:      attestedResponder(t, intermediate.Issue(t, "metadata.example.com"), ""),
+			expectedResult: false,
+		},
+		{
+			name:           "Document signed by an untrusted certificate",
+			trust:          trust,
+			responder:      attestedResponder(t, otherRoot.Issue(t, "metadata.azure.com"), ""),
+			expectedResult: false,
+		},
+		{
+			name:           "Missing intermediates",
+			trust:          fstest.MapFS{"azure/roots.pem": {Data: root.PEM()}},
+			responder:      attestedResponder(t, intermediate.Issue(t, "metadata.azure.com"), ""),
+			expectedResult: false,
+		},
+		{
+			name:           "Intermediates fetched from the issuer URL",
+			trust:          fstest.MapFS{"azure/roots.pem": {Data: root.PEM()}},
+			responder:      attestedResponder(t, published.Issue(t, "metadata.azure.com"), ""),
+			issuer:         httpmock.NewBytesResponder(http.StatusOK, published.Cert.Raw),
+			expectedResult: true,
+		},
+		{
+			name:           "Untrusted intermediate fetched from the issuer URL",
+			trust:          fstest.MapFS{"azure/roots.pem": {Data: otherRoot.PEM()}},
+			responder:      attestedResponder(t, published.Issue(t, "metadata.azure.com"), ""),
+			issuer:         httpmock.NewBytesResponder(http.StatusOK, published.Cert.Raw),
+			expectedResult: false,
+		},
+		{
+			name:           "Issuer URL unavailable",
+			trust:          fstest.MapFS{"azure/roots.pem": {Data: root.PEM()}},
+			responder:      attestedResponder(t, published.Issue(t, "metadata.azure.com"), ""),
+			issuer:         httpmock.NewStringResponder(http.StatusNotFound, ""),
+			expectedResult: false,
+		},
+		{
+			name:           "System roots without roots file",
+			trust:          fstest.MapFS{},
+			responder:      attestedResponder(t, intermediate.Issue(t, "metadata.azure.com"), ""),
+			expectedResult: false,
+		},
+		{
+			name:           "Unsupported encoding",
+			trust:          trust,
+			responder:      httpmock.NewJsonResponderOrPanic(http.StatusOK, attestedResponse{Encoding: "jws", Signature: "e30"}),
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			// The nonce is appended to the query, so only the beginning of the URL is matched.
+			httpmock.RegisterRegexpResponder("GET", regexp.MustCompile("^"+regexp.QuoteMeta(attestedURL+"&nonce=")), tt.responder)
+			if tt.issuer != nil {
+				httpmock.RegisterResponder("GET", published.URL, tt.issuer)
+			}
+
+			a := &Azure{}
+			ctx := attest.WithTrust(context.Background(), tt.trust)
+
+			if result := a.Attest(ctx, zap.NewNop()); result != tt.expectedResult {
+				t.Errorf("Attest() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestVerifyOptions(t *testing.T) {
+	a := &Azure{}
+
+	opts, err := a.verifyOptions(fstest.MapFS{})
+	if err != nil {
+		t.Fatalf("verifyOptions() without roots file error = %v", err)
+	}
+	if opts.Roots == nil {
+		t.Error("verifyOptions() without roots file = no roots; want the system roots")
+	}
+
+	if _, err = a.verifyOptions(attest.Trust(context.Background())); err != nil {
+		t.Errorf("verifyOptions() with the embedded trust material error = %v", err)
+	}
+
+	if _, err = a.verifyOptions(fstest.MapFS{"azure/roots.pem": {Data: []byte("invalid")}}); err == nil {
+		t.Error("verifyOptions() with invalid roots file error = nil; want an error")
+	}
+}
+
+func TestCheckMACAddressFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		address            string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "Azure MAC address",
+			address:            "00:22:48:12:34:56",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other MAC address",
+			address:        "42:01:0a:80:00:02",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testutil.CreateRoot(t, map[string]string{"/eth0/address": tt.address + "\n", "/eth0/device/uevent": ""})
+
+			a := &Azure{}
+			logger := zap.NewNop()
+			confidence, result := a.checkMACAddressFiles(filepath.Join(dir, "*", "address"), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMACAddressFiles() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
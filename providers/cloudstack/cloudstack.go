@@ -0,0 +1,165 @@
+// Package cloudstack implements the Apache CloudStack cloud provider detection.
+//
+// CloudStack does not serve metadata on the link-local address. Instead, the virtual router that acts
+// as the DHCP server of the guest network serves it, so its address is read from the local DHCP leases.
+// As that is any network's DHCP server, it is only queried once the host is known to be a CloudStack guest.
+package cloudstack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/exoscale"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	metadataPath    string = "/latest/meta-data/"
+	productNameFile        = "/sys/class/dmi/id/product_name"
+	identifier             = types.CloudStack
+)
+
+// productNamePrefix is the prefix of the product name CloudStack sets on its KVM guests, e.g. "CloudStack KVM Hypervisor".
+const productNamePrefix = "CloudStack"
+
+// specializations are CloudStack-based providers that take precedence over generic CloudStack detection.
+var specializations = []specialization{&exoscale.Exoscale{}}
+
+// specialization is a CloudStack-based provider that can report whether it matches the host.
+type specialization interface {
+	Identifier() types.ProviderId
+	Matches(context.Context, string, *zap.Logger) bool
+}
+
+type CloudStack struct{}
+
+func (c *CloudStack) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (c *CloudStack) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if c.deferToSpecialization(ctx, root, logger) {
+		return
+	}
+
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: c.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	file := filepath.Join(root, productNameFile)
+	if !probe.File(ctx, identifier, "product_name file", file, func() bool { return c.checkProductNameFile(file, logger) }) &&
+		!cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		return
+	}
+
+	patterns := make([]string, len(leaseFiles))
+	for i, pattern := range leaseFiles {
+		patterns[i] = filepath.Join(root, pattern)
+	}
+
+	if probe.Request(ctx, identifier, "virtual router metadata", strings.Join(patterns, ", "), func(ctx context.Context) bool { return c.checkMetadataServer(ctx, patterns, logger) }) {
+		ch <- types.Evidence{Provider: c.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	ch <- types.Evidence{Provider: c.Identifier(), Confidence: types.MediumConfidence}
+}
+
+func (c *CloudStack) deferToSpecialization(ctx context.Context, root string, logger *zap.Logger) bool {
+	for _, s := range specializations {
+		// An excluded specialization isn't checked, so its hosts are reported as CloudStack instead.
+		if !detection.Enabled(ctx, s.Identifier()) {
+			continue
+		}
+
+		// The specialization's checks run once per detection and are recorded as its own.
+		if probe.Run(ctx, identifier, fmt.Sprintf("%s specialization", s.Identifier()), string(s.Identifier()), func() bool {
+			return s.Matches(ctx, root, logger)
+		}) {
+			logger.Debug(fmt.Sprintf("Deferring %s detection to %s", identifier, s.Identifier()))
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CloudStack) checkProductNameFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s product name using file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.HasPrefix(strings.TrimSpace(string(content)), productNamePrefix)
+}
+
+func (c *CloudStack) checkMetadataServer(ctx context.Context, leaseFiles []string, logger *zap.Logger) bool {
+	routers := findVirtualRouters(leaseFiles, logger)
+	if len(routers) == 0 {
+		logger.Debug(fmt.Sprintf("No DHCP server found for %s metadata", identifier))
+		return false
+	}
+
+	for _, router := range routers {
+		if c.checkVirtualRouter(ctx, router, logger) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CloudStack) checkVirtualRouter(ctx context.Context, router netip.Addr, logger *zap.Logger) bool {
+	metadataURL := fmt.Sprintf("http://%s%s", netip.AddrPortFrom(router, 80), metadataPath)
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return false
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response body: %s", err))
+		return false
+	}
+
+	// The metadata index lists CloudStack-specific entries alongside the EC2-compatible ones.
+	entries := strings.Fields(string(text))
+	return slices.Contains(entries, "service-offering") && slices.Contains(entries, "vm-id")
+}
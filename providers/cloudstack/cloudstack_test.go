@@ -0,0 +1,237 @@
+package cloudstack
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/exoscale"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const exoscaleMetadataURL = "http://169.254.169.254/latest/meta-data/availability-zone"
+
+const metadataIndex = `service-offering
+availability-zone
+local-ipv4
+local-hostname
+public-ipv4
+public-hostname
+instance-id
+vm-id
+public-keys
+cloud-identifier
+hypervisor-host-name
+`
+
+func TestIdentifier(t *testing.T) {
+	c := &CloudStack{}
+	if c.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", c.Identifier(), identifier)
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMocks     func()
+		expectedResult bool
+	}{
+		{
+			name: "Virtual router serves CloudStack metadata",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", "http://10.1.1.1:80/latest/meta-data/",
+					httpmock.NewStringResponder(http.StatusOK, metadataIndex))
+			},
+			expectedResult: true,
+		},
+		{
+			name: "DHCP server serves EC2-style metadata",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", "http://10.1.1.1:80/latest/meta-data/",
+					httpmock.NewStringResponder(http.StatusOK, "ami-id\ninstance-id\nlocal-ipv4\n"))
+			},
+			expectedResult: false,
+		},
+		{
+			name: "DHCP server does not serve metadata",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", "http://10.1.1.1:80/latest/meta-data/",
+					httpmock.NewStringResponder(http.StatusNotFound, ""))
+			},
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			c := &CloudStack{}
+			logger := zap.NewNop()
+			result := c.checkMetadataServer(context.Background(), []string{filepath.Join("testdata", "networkd", "*")}, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMetadataServerNoLeases(t *testing.T) {
+	c := &CloudStack{}
+	logger := zap.NewNop()
+	if c.checkMetadataServer(context.Background(), []string{filepath.Join("testdata", "missing", "*")}, logger) {
+		t.Error("Expected checkMetadataServer to return false without leases")
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name               string
+		root               string
+		routerStatus       int
+		expectedConfidence types.Confidence
+		expectedRequests   int
+	}{
+		{
+			name:               "Virtual router serves CloudStack metadata",
+			root:               "guest",
+			routerStatus:       http.StatusOK,
+			expectedConfidence: types.HighConfidence,
+			expectedRequests:   1,
+		},
+		{
+			name:               "CloudStack product name without metadata",
+			root:               "guest",
+			routerStatus:       http.StatusNotFound,
+			expectedConfidence: types.MediumConfidence,
+			expectedRequests:   1,
+		},
+		{
+			name:               "DHCP server of another network",
+			root:               "host",
+			routerStatus:       http.StatusOK,
+			expectedConfidence: types.NoConfidence,
+			expectedRequests:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("GET", "http://10.1.1.1:80/latest/meta-data/",
+				httpmock.NewStringResponder(tt.routerStatus, metadataIndex))
+
+			// Exoscale is excluded, so the checks don't wait on its metadata server.
+			ctx := detection.WithRun(context.Background(), detection.NewRun(func(id types.ProviderId) bool { return id != types.Exoscale }))
+
+			c := &CloudStack{}
+			ch := make(chan types.Evidence, 1)
+			c.Identify(ctx, ch, filepath.Join("testdata", tt.root), zap.NewNop())
+
+			confidence := types.NoConfidence
+			if len(ch) > 0 {
+				confidence = (<-ch).Confidence
+			}
+			if confidence != tt.expectedConfidence {
+				t.Errorf("Identify() confidence = %v; want %v", confidence, tt.expectedConfidence)
+			}
+
+			if requests := httpmock.GetTotalCallCount(); requests != tt.expectedRequests {
+				t.Errorf("Identify() made %d requests; want %d", requests, tt.expectedRequests)
+			}
+		})
+	}
+}
+
+func TestIdentifyWithoutSpecialization(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", exoscaleMetadataURL, httpmock.NewStringResponder(http.StatusOK, "ch-gva-2"))
+	httpmock.RegisterResponder("GET", "http://10.1.1.1:80/latest/meta-data/",
+		httpmock.NewStringResponder(http.StatusOK, metadataIndex))
+
+	ctx := detection.WithRun(context.Background(), detection.NewRun(func(id types.ProviderId) bool { return id != types.Exoscale }))
+
+	c := &CloudStack{}
+	ch := make(chan types.Evidence, 1)
+	c.Identify(ctx, ch, filepath.Join("testdata", "guest"), zap.NewNop())
+
+	if len(ch) == 0 {
+		t.Fatal("Identify() = no result; want cloudstack")
+	}
+	if result := <-ch; result.Provider != identifier {
+		t.Errorf("Identify() = %v; want %v", result.Provider, identifier)
+	}
+	if calls := httpmock.GetCallCountInfo()["GET "+exoscaleMetadataURL]; calls != 0 {
+		t.Errorf("Identify() requested the Exoscale metadata %d times; want 0", calls)
+	}
+}
+
+func TestIdentifySharesSpecializationChecks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", exoscaleMetadataURL, httpmock.NewStringResponder(http.StatusOK, "ch-gva-2"))
+
+	ctx := detection.WithRun(context.Background(), detection.NewRun(func(types.ProviderId) bool { return true }))
+
+	ch := make(chan types.Evidence, 2)
+	root := testutil.CreateRoot(t, nil)
+	(&exoscale.Exoscale{}).Identify(ctx, ch, root, zap.NewNop())
+	(&CloudStack{}).Identify(ctx, ch, root, zap.NewNop())
+
+	if result := <-ch; result.Provider != types.Exoscale {
+		t.Errorf("Identify() = %v; want %v", result.Provider, types.Exoscale)
+	}
+	if len(ch) != 0 {
+		t.Errorf("CloudStack Identify() = %v; want no result", (<-ch).Provider)
+	}
+	if calls := httpmock.GetCallCountInfo()["GET "+exoscaleMetadataURL]; calls != 1 {
+		t.Errorf("Exoscale metadata requested %d times; want 1", calls)
+	}
+}
+
+func TestCheckProductNameFile(t *testing.T) {
+	c := &CloudStack{}
+	logger := zap.NewNop()
+
+	if !c.checkProductNameFile(filepath.Join("testdata", "guest", productNameFile), logger) {
+		t.Error("checkProductNameFile() = false; want true for a CloudStack guest")
+	}
+	if c.checkProductNameFile(filepath.Join("testdata", "host", productNameFile), logger) {
+		t.Error("checkProductNameFile() = true; want false for another product")
+	}
+}
+
+func TestIdentifyDefersToSpecialization(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", exoscaleMetadataURL, httpmock.NewStringResponder(http.StatusOK, "ch-gva-2"))
+
+	c := &CloudStack{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+
+	c.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+	select {
+	case result := <-ch:
+		t.Errorf("Identify() = %v; want no result", result.Provider)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,100 @@
+package cloudstack
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	dhclientServerOption = "option dhcp-server-identifier "
+	networkdServerKey    = "SERVER_ADDRESS="
+)
+
+// leaseFiles are the glob patterns of the lease files written by dhclient, systemd-networkd and NetworkManager.
+var leaseFiles = []string{
+	"/var/lib/dhclient/*.lease*",
+	"/var/lib/dhcp/*.lease*",
+	"/run/systemd/netif/leases/*",
+	"/var/lib/NetworkManager/*.lease",
+}
+
+var errNoServerAddress = errors.New("no DHCP server address found")
+
+// parseLease returns the DHCP server address recorded in a lease.
+//
+// Both the dhclient format (also used by NetworkManager's dhclient backend) and the KEY=VALUE format
+// of systemd-networkd and NetworkManager's internal client are supported. dhclient appends renewed
+// leases to the same file, so the last server address in the lease wins.
+func parseLease(r io.Reader) (netip.Addr, error) {
+	var server string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, dhclientServerOption):
+			server = strings.TrimSuffix(strings.TrimPrefix(line, dhclientServerOption), ";")
+		case strings.HasPrefix(line, networkdServerKey):
+			server = strings.TrimPrefix(line, networkdServerKey)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return netip.Addr{}, err
+	}
+
+	if server == "" {
+		return netip.Addr{}, errNoServerAddress
+	}
+
+	return netip.ParseAddr(strings.TrimSpace(server))
+}
+
+// findVirtualRouters returns the unique DHCP server addresses found in the lease files matching the given patterns.
+func findVirtualRouters(patterns []string, logger *zap.Logger) []netip.Addr {
+	var routers []netip.Addr
+
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error matching lease files %s: %s", pattern, err))
+			continue
+		}
+
+		for _, file := range files {
+			addr, err := parseLeaseFile(file)
+			if err != nil {
+				logger.Debug(fmt.Sprintf("Skipping lease file %s: %s", file, err))
+				continue
+			}
+
+			if !slices.Contains(routers, addr) {
+				routers = append(routers, addr)
+			}
+		}
+	}
+
+	return routers
+}
+
+func parseLeaseFile(file string) (netip.Addr, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return parseLease(f)
+}
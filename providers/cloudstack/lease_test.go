@@ -0,0 +1,87 @@
+package cloudstack
+
+import (
+	"net/netip"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseLeaseFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           string
+		expectedResult netip.Addr
+		expectError    bool
+	}{
+		{
+			name:           "dhclient lease uses the most recent lease",
+			file:           "dhclient.eth0.leases",
+			expectedResult: netip.MustParseAddr("10.1.1.254"),
+		},
+		{
+			name:           "systemd-networkd lease",
+			file:           filepath.Join("networkd", "2"),
+			expectedResult: netip.MustParseAddr("10.1.1.1"),
+		},
+		{
+			name:           "NetworkManager internal lease",
+			file:           filepath.Join("NetworkManager", "internal-5f1c2b9e-0c0a-4a8e-9d7b-1f0e6a3c2d11-eth1.lease"),
+			expectedResult: netip.MustParseAddr("192.168.100.1"),
+		},
+		{
+			name:        "Lease without server address",
+			file:        filepath.Join("NetworkManager", "empty.lease"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseLeaseFile(filepath.Join("testdata", tt.file))
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("parseLeaseFile() = %v; want error", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result != tt.expectedResult {
+				t.Errorf("parseLeaseFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestParseLeaseInvalidAddress(t *testing.T) {
+	if _, err := parseLease(strings.NewReader("SERVER_ADDRESS=not-an-address\n")); err == nil {
+		t.Error("Expected parseLease to return an error")
+	}
+}
+
+func TestFindVirtualRouters(t *testing.T) {
+	patterns := []string{
+		filepath.Join("testdata", "*.leases"),
+		filepath.Join("testdata", "networkd", "*"),
+		filepath.Join("testdata", "NetworkManager", "*.lease"),
+		filepath.Join("testdata", "missing", "*"),
+	}
+
+	result := findVirtualRouters(patterns, zap.NewNop())
+	expected := []netip.Addr{
+		netip.MustParseAddr("10.1.1.254"),
+		netip.MustParseAddr("10.1.1.1"),
+		netip.MustParseAddr("192.168.100.1"),
+	}
+
+	if !slices.Equal(result, expected) {
+		t.Errorf("findVirtualRouters() = %v; want %v", result, expected)
+	}
+}
@@ -7,11 +7,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -31,14 +36,32 @@ func (d *DigitalOcean) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (d *DigitalOcean) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if d.checkMetadataServer(ctx, logger) {
-		ch <- d.Identifier()
+func (d *DigitalOcean) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: d.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
 		return
 	}
 
-	if d.checkVendorFile(vendorFile, logger) {
-		ch <- d.Identifier()
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return d.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: d.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return d.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: d.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: d.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// Droplets are assigned their public addresses directly. The published ranges are only a hint, as
+	// addresses are moved between networks faster than snapshots of the ranges are refreshed.
+	addrs := network.InterfaceAddrs(root, logger)
+	if probe.Run(ctx, identifier, "interface addresses", "interfaces", func() bool { return d.checkInterfaceAddresses(addrs, logger) }) {
+		ch <- types.Evidence{Provider: d.Identifier(), Confidence: types.LowConfidence}
 		return
 	}
 }
@@ -46,7 +69,7 @@ func (d *DigitalOcean) Identify(ctx context.Context, ch chan<- types.ProviderId,
 func (d *DigitalOcean) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
@@ -90,3 +113,14 @@ func (d *DigitalOcean) checkVendorFile(file string, logger *zap.Logger) bool {
 
 	return strings.Contains(string(content), "DigitalOcean")
 }
+
+func (d *DigitalOcean) checkInterfaceAddresses(addrs []netip.Addr, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s interface addresses", identifier))
+
+	addr, ok := network.OwnedAddr(identifier, addrs)
+	if ok {
+		logger.Debug(fmt.Sprintf("Interface address %s is in a published %s range", addr, identifier))
+	}
+
+	return ok
+}
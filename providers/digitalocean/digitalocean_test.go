@@ -3,6 +3,7 @@ package digitalocean
 import (
 	"context"
 	"net/http"
+	"net/netip"
 	"os"
 	"testing"
 	"time"
@@ -10,6 +11,8 @@ import (
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -55,15 +58,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMocks()
 
 			d := &DigitalOcean{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go d.Identify(context.Background(), ch, logger)
+			go d.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -121,6 +124,21 @@ func TestCheckMetadataServer(t *testing.T) {
 	}
 }
 
+func TestCheckMetadataServerUpCloud(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// UpCloud serves its metadata on the same path as DigitalOcean.
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(http.StatusOK,
+		`{"cloud_name": "upcloud", "instance_id": "00a1b2c3-d4e5-f678-9012-3456789abcde"}`))
+
+	d := &DigitalOcean{}
+	logger := zap.NewNop()
+	if d.checkMetadataServer(context.Background(), logger) {
+		t.Error("Expected checkMetadataServer to return false for UpCloud metadata")
+	}
+}
+
 func TestCheckVendorFile(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -159,3 +177,22 @@ func TestCheckVendorFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckInterfaceAddresses(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("104.131.0.0/18"), Provider: identifier})
+	iprange.SetDefault(&table)
+
+	d := &DigitalOcean{}
+	logger := zap.NewNop()
+
+	if !d.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("104.131.0.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return true with an address in a published range")
+	}
+
+	if d.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("198.51.100.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return false without an address in a published range")
+	}
+}
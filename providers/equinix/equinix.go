@@ -0,0 +1,114 @@
+// Package equinix implements the Equinix Metal bare-metal provider detection.
+package equinix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	metadataURL string = "https://metadata.platformequinix.com/metadata"
+	vendorFile         = "/sys/class/dmi/id/sys_vendor"
+	identifier         = types.Equinix
+)
+
+// serverVendors are the hardware vendors Equinix Metal servers are built by.
+// They are sold to anyone, so a match is only reported with low confidence.
+var serverVendors = []string{"Dell Inc.", "Supermicro"}
+
+type metadataResponse struct {
+	ID       string `json:"id"`
+	Facility string `json:"facility"`
+	Metro    string `json:"metro"`
+}
+
+type Equinix struct{}
+
+func (e *Equinix) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (e *Equinix) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	var metadata *metadataResponse
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) (ok bool) {
+		metadata, ok = e.checkMetadataServer(ctx, logger)
+		return ok
+	}) {
+		logger.Debug(fmt.Sprintf("Found %s facility %s in metro %s", identifier, metadata.Facility, metadata.Metro))
+		ch <- types.Evidence{
+			Provider:   e.Identifier(),
+			Confidence: types.HighConfidence,
+			Metadata: types.Metadata{
+				InstanceID: metadata.ID,
+				Region:     metadata.Metro,
+				Zone:       metadata.Facility,
+			},
+		}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return e.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: e.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+}
+
+func (e *Equinix) checkMetadataServer(ctx context.Context, logger *zap.Logger) (*metadataResponse, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return nil, false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return nil, false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return nil, false
+	}
+
+	metadata := new(metadataResponse)
+	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return nil, false
+	}
+
+	return metadata, strings.TrimSpace(metadata.ID) != "" && strings.TrimSpace(metadata.Facility) != ""
+}
+
+func (e *Equinix) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return slices.Contains(serverVendors, strings.TrimSpace(string(content)))
+}
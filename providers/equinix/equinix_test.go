@@ -0,0 +1,149 @@
+package equinix
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "vendorfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentify(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewJsonResponderOrPanic(200, metadataResponse{
+		ID:       "6e8d1a2c-3f4b-4c5d-8e9f-0a1b2c3d4e5f",
+		Facility: "da11",
+		Metro:    "da",
+	}))
+
+	e := &Equinix{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+
+	go e.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+	select {
+	case result := <-ch:
+		if result.Provider != identifier {
+			t.Errorf("Identify() = %v; want %v", result.Provider, identifier)
+		}
+		if result.Confidence != types.HighConfidence {
+			t.Errorf("Identify() confidence = %v; want %v", result.Confidence, types.HighConfidence)
+		}
+		want := types.Metadata{InstanceID: "6e8d1a2c-3f4b-4c5d-8e9f-0a1b2c3d4e5f", Region: "da", Zone: "da11"}
+		if result.Metadata != want {
+			t.Errorf("Identify() metadata = %+v; want %+v", result.Metadata, want)
+		}
+	case <-time.After(time.Second):
+		t.Error("Identify() timed out")
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedResult bool
+	}{
+		{
+			name:           "Valid metadata response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id": "6e8d1a2c-3f4b-4c5d-8e9f-0a1b2c3d4e5f", "facility": "sv15", "metro": "sv"}`,
+			expectedResult: true,
+		},
+		{
+			name:           "Missing facility",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id": "6e8d1a2c-3f4b-4c5d-8e9f-0a1b2c3d4e5f"}`,
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusNotFound,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			e := &Equinix{}
+			logger := zap.NewNop()
+			_, result := e.checkMetadataServer(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Equinix server vendor",
+			fileContent:    "Supermicro\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "QEMU",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			e := &Equinix{}
+			logger := zap.NewNop()
+			result := e.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Package exoscale implements the Exoscale cloud provider detection.
+package exoscale
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	metadataURL string = "http://169.254.169.254/latest/meta-data/availability-zone"
+	vendorFile         = "/sys/class/dmi/id/sys_vendor"
+	identifier         = types.Exoscale
+)
+
+// zones are the Exoscale zones known when this was written.
+var zones = []string{"at-vie-1", "at-vie-2", "bg-sof-1", "ch-dk-2", "ch-gva-2", "de-fra-1", "de-muc-1", "hr-zag-1"}
+
+// zonePattern matches the names Exoscale gives its zones, <country>-<city>-<n>, so that zones opened since are
+// still recognized, if only as a hint.
+var zonePattern = regexp.MustCompile(`^[a-z]{2}-[a-z]{2,4}-\d+$`)
+
+type Exoscale struct{}
+
+func (e *Exoscale) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (e *Exoscale) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if confidence := e.match(ctx, root, logger); confidence != types.NoConfidence {
+		ch <- types.Evidence{Provider: e.Identifier(), Confidence: confidence}
+		return
+	}
+}
+
+// Matches reports whether the host is an Exoscale instance, with more than low confidence.
+//
+// Exoscale is built on CloudStack, so the CloudStack provider uses this to defer to Exoscale. The checks run once
+// per detection, whichever provider asks first.
+func (e *Exoscale) Matches(ctx context.Context, root string, logger *zap.Logger) bool {
+	return e.match(ctx, root, logger) > types.LowConfidence
+}
+
+func (e *Exoscale) match(ctx context.Context, root string, logger *zap.Logger) types.Confidence {
+	return detection.Match(ctx, identifier, func() types.Confidence { return e.check(ctx, root, logger) })
+}
+
+func (e *Exoscale) check(ctx context.Context, root string, logger *zap.Logger) types.Confidence {
+	if _, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		return types.HighConfidence
+	}
+
+	// A zone that isn't known yet is only a hint, as any CloudStack cloud can name its zones the same way.
+	var zoneConfidence types.Confidence
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) (ok bool) {
+		zoneConfidence, ok = e.checkMetadataServer(ctx, logger)
+		return ok
+	}) && zoneConfidence == types.HighConfidence {
+		return types.HighConfidence
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return e.checkVendorFile(file, logger) }) {
+		return types.MediumConfidence
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		return types.MediumConfidence
+	}
+
+	return zoneConfidence
+}
+
+// checkMetadataServer checks the availability zone of the instance. Known zones are reported with high confidence,
+// and other zones named like Exoscale's with low confidence.
+func (e *Exoscale) checkMetadataServer(ctx context.Context, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return types.NoConfidence, false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return types.NoConfidence, false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return types.NoConfidence, false
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response body: %s", err))
+		return types.NoConfidence, false
+	}
+
+	zone := strings.ToLower(strings.TrimSpace(string(text)))
+	switch {
+	case slices.Contains(zones, zone):
+		return types.HighConfidence, true
+	case zonePattern.MatchString(zone):
+		logger.Debug(fmt.Sprintf("Unknown %s zone %s", identifier, zone))
+		return types.LowConfidence, true
+	}
+
+	return types.NoConfidence, false
+}
+
+func (e *Exoscale) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(string(content), "Exoscale")
+}
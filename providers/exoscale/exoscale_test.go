@@ -0,0 +1,185 @@
+package exoscale
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "vendorfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupMocks       func()
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify Exoscale via metadata server",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, "ch-gva-2"))
+			},
+			expectedProvider: identifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			e := &Exoscale{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			go e.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			case <-time.After(time.Second):
+				t.Error("Identify() timed out")
+			}
+		})
+	}
+}
+
+func TestIdentifyUnknownZone(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, "it-mil-1"))
+
+	e := &Exoscale{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+	root := t.TempDir()
+
+	e.Identify(context.Background(), ch, root, logger)
+	if result := <-ch; result.Confidence != types.LowConfidence {
+		t.Errorf("Identify() confidence = %v; want %v", result.Confidence, types.LowConfidence)
+	}
+
+	// CloudStack doesn't defer to Exoscale on a hint alone.
+	if e.Matches(context.Background(), root, logger) {
+		t.Error("Matches() with an unknown zone = true; want false")
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name               string
+		responseStatus     int
+		responseBody       string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "Exoscale zone",
+			responseStatus:     http.StatusOK,
+			responseBody:       "de-fra-1\n",
+			expectedConfidence: types.HighConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:               "New Exoscale zone",
+			responseStatus:     http.StatusOK,
+			responseBody:       "it-mil-1\n",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other zone",
+			responseStatus: http.StatusOK,
+			responseBody:   "us-east-1a",
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusNotFound,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			e := &Exoscale{}
+			logger := zap.NewNop()
+			confidence, result := e.checkMetadataServer(context.Background(), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Vendor file contains Exoscale",
+			fileContent:    "Exoscale",
+			expectedResult: true,
+		},
+		{
+			name:           "Vendor file does not contain Exoscale",
+			fileContent:    "This machine is by Another Vendor",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			e := &Exoscale{}
+			logger := zap.NewNop()
+			result := e.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
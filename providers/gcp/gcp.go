@@ -3,56 +3,253 @@ package gcp
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
 const (
-	metadataURL string = "http://metadata.google.internal/computeMetadata/v1/instance/tags"
-	vendorFile         = "/sys/class/dmi/id/product_name"
-	identifier         = types.Gcp
+	metadataURL string = "http://metadata.google.internal/computeMetadata/v1/"
+	// metadataIPURL is used when metadata.google.internal can't be resolved, e.g. with a custom DNS server.
+	metadataIPURL  string = "http://169.254.169.254/computeMetadata/v1/"
+	zonePath              = "instance/zone"
+	instanceIDPath        = "instance/id"
+	projectIDPath         = "project/project-id"
+	identityPath          = "instance/service-accounts/default/identity"
+	// jwksURL serves the keys Google signs identity tokens with. They are rotated regularly, so they are
+	// fetched when the trust material doesn't include them.
+	jwksURL        string = "https://www.googleapis.com/oauth2/v3/certs"
+	vendorFile            = "/sys/class/dmi/id/product_name"
+	biosVendorFile        = "/sys/class/dmi/id/bios_vendor"
+	diskModelFiles        = "/sys/block/*/device/model"
+	diskLinks             = "/dev/disk/by-id/google-*"
+	identifier            = types.Gcp
+
+	functionTargetEnv = "FUNCTION_TARGET"
+	functionNameEnv   = "FUNCTION_NAME"
+	cloudRunJobEnv    = "CLOUD_RUN_JOB"
+	knativeServiceEnv = "K_SERVICE"
+	appEngineEnv      = "GAE_APPLICATION"
+	kubernetesHostEnv = "KUBERNETES_SERVICE_HOST"
 )
 
+// zonePattern matches the zone reported by the GCP metadata server, e.g. projects/123456789/zones/us-central1-a.
+// GCP-compatible clouds such as Yandex Cloud serve the same endpoint, but with their own project and zone formats.
+var zonePattern = regexp.MustCompile(`^projects/\d+/zones/([a-z]+-[a-z]+\d+)-[a-z]$`)
+
+// zoneNamePattern matches a zone name, e.g. us-central1-a.
+var zoneNamePattern = regexp.MustCompile(`^([a-z]+-[a-z]+\d+)-[a-z]$`)
+
+// instanceIDPattern matches the numeric instance ID assigned by Compute Engine.
+var instanceIDPattern = regexp.MustCompile(`^\d+$`)
+
+// projectIDPattern matches a project ID, optionally scoped to a domain (e.g. example.com:my-project).
+var projectIDPattern = regexp.MustCompile(`^([a-z0-9.-]+:)?[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+
+// diskModels are the models of Persistent Disk volumes, attached as SCSI or NVMe devices.
+var diskModels = []string{"PersistentDisk", "nvme_card-pd"}
+
+// agentConfigFiles are installed with the Google guest agent. Images built for GCP keep them when run
+// elsewhere, so a match is only reported with low confidence.
+var agentConfigFiles = []string{
+	"/etc/default/instance_configs.cfg",
+	"/etc/default/instance_configs.cfg.template",
+}
+
+// issuers are the issuers of the identity tokens of Compute Engine instances.
+var issuers = []string{"https://accounts.google.com", "accounts.google.com"}
+
+// identityClaims are the claims of an identity token requested in the full format.
+type identityClaims struct {
+	Issuer   string `json:"iss"`
+	Audience string `json:"aud"`
+	Expiry   int64  `json:"exp"`
+	Google   struct {
+		ComputeEngine struct {
+			InstanceID string `json:"instance_id"`
+			ProjectID  string `json:"project_id"`
+			Zone       string `json:"zone"`
+		} `json:"compute_engine"`
+	} `json:"google"`
+}
+
 type Gcp struct{}
 
 func (g *Gcp) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (g *Gcp) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if g.checkMetadataServer(ctx, logger) {
-		ch <- g.Identifier()
+func (g *Gcp) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	// Managed runtimes may not expose the metadata server, so they are checked first.
+	var platform types.Platform
+	var confidence types.Confidence
+	if probe.Run(ctx, identifier, "environment", strings.Join([]string{functionTargetEnv, functionNameEnv, cloudRunJobEnv, knativeServiceEnv, appEngineEnv}, ", "), func() (ok bool) {
+		platform, confidence, ok = g.checkEnvironment(logger)
+		return ok
+	}) {
+		// Cloud Run services share their environment variables with any Knative service, and functions with
+		// local runs of the Functions Framework, so they are only reported with more than low confidence once
+		// the metadata server confirms them.
+		var metadata types.Metadata
+		if confidence == types.LowConfidence && probe.Request(ctx, identifier, "metadata server project", metadataURL+projectIDPath, func(ctx context.Context) (ok bool) {
+			metadata, ok = g.checkProject(ctx, logger)
+			return ok
+		}) {
+			confidence = types.HighConfidence
+		}
+
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: confidence, Platform: platform, Metadata: metadata}
 		return
 	}
 
-	if g.checkVendorFile(vendorFile, logger) {
-		ch <- g.Identifier()
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	var metadata types.Metadata
+	if probe.Request(ctx, identifier, "metadata server", metadataURL+zonePath, func(ctx context.Context) (ok bool) {
+		metadata, ok = g.checkMetadataServer(ctx, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.HighConfidence, Metadata: metadata}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return g.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, biosVendorFile); probe.File(ctx, identifier, "bios_vendor file", file, func() bool { return g.checkBiosVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if pattern := filepath.Join(root, diskModelFiles); probe.Run(ctx, identifier, "disk model files", pattern, func() bool { return g.checkDiskModelFiles(pattern, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// The guest environment's udev rules (google_nvme_id for NVMe disks) name every disk google-<device name>.
+	if pattern := filepath.Join(root, diskLinks); probe.Run(ctx, identifier, "disk links", pattern, func() bool { return g.checkDiskLinks(pattern, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// Compute Engine derives the MAC addresses of its instances from their internal addresses (42:01:<address>).
+	if pattern := filepath.Join(root, network.MACAddressFiles); probe.Run(ctx, identifier, "mac address files", pattern, func() (ok bool) {
+		confidence, ok = g.checkMACAddressFiles(pattern, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: confidence}
+		return
+	}
+
+	files := make([]string, len(agentConfigFiles))
+	for i, file := range agentConfigFiles {
+		files[i] = filepath.Join(root, file)
+	}
+
+	if probe.Run(ctx, identifier, "guest agent config files", strings.Join(files, ", "), func() bool { return g.checkAgentConfigFiles(files, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+
+	// The network configuration is only a hint, as any host can use the same names and addresses.
+	if file := filepath.Join(root, network.ResolvConfFile); probe.File(ctx, identifier, "resolv.conf search domains", file, func() (ok bool) {
+		metadata, ok = g.checkResolvConfFile(file, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.LowConfidence, Metadata: metadata}
+		return
+	}
+
+	if file := filepath.Join(root, network.HostnameFile); probe.File(ctx, identifier, "hostname file", file, func() (ok bool) {
+		metadata, ok = g.checkHostnameFile(file, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.LowConfidence, Metadata: metadata}
+		return
+	}
+
+	addrs := network.InterfaceAddrs(root, logger)
+	if probe.Run(ctx, identifier, "interface addresses", "interfaces", func() bool { return g.checkInterfaceAddresses(addrs, logger) }) {
+		ch <- types.Evidence{Provider: g.Identifier(), Confidence: types.LowConfidence}
 		return
 	}
 }
 
-func (g *Gcp) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
-	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+func (g *Gcp) checkEnvironment(logger *zap.Logger) (types.Platform, types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s managed runtime environment variables", identifier))
+
+	lookup := func(name string) bool {
+		_, ok := os.LookupEnv(name)
+		return ok
+	}
+
+	// Knative sets K_SERVICE on any Kubernetes cluster, where pods always have KUBERNETES_SERVICE_HOST set.
+	knativeService := lookup(knativeServiceEnv) && os.Getenv(kubernetesHostEnv) == ""
+
+	// Cloud Functions (2nd gen) run on Cloud Run and also set K_SERVICE, so they are checked first. The Functions
+	// Framework sets FUNCTION_TARGET on local runs too, so it is only a hint on its own.
+	switch {
+	case lookup(functionNameEnv) || lookup(functionTargetEnv) && knativeService:
+		return types.CloudFunctions, types.MediumConfidence, true
+	case lookup(functionTargetEnv):
+		return types.CloudFunctions, types.LowConfidence, true
+	case lookup(cloudRunJobEnv):
+		return types.CloudRun, types.MediumConfidence, true
+	case lookup(appEngineEnv):
+		return types.AppEngine, types.MediumConfidence, true
+	case knativeService:
+		// Outside of Kubernetes, K_SERVICE is still only a hint.
+		return types.CloudRun, types.LowConfidence, true
+	}
+
+	return types.NoPlatform, types.NoConfidence, false
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+// get returns the value of a metadata entry. Only responses carrying the Metadata-Flavor: Google header
+// are accepted, which proxies and captive portals answering in place of the metadata server don't send.
+func (g *Gcp) get(ctx context.Context, url string, logger *zap.Logger) (string, error) {
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error creating request: %s", err))
-		return false
+		return "", err
 	}
 	req.Header.Add("Metadata-Flavor", "Google")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error sending request: %s", err))
-		return false
+		return "", err
 	}
 	defer func(Body io.ReadCloser) {
 		closeErr := Body.Close()
@@ -61,7 +258,96 @@ func (g *Gcp) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool
 		}
 	}(resp.Body)
 
-	return resp.StatusCode == http.StatusOK
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+
+	if flavor := resp.Header.Get("Metadata-Flavor"); flavor != "Google" {
+		return "", fmt.Errorf("unexpected Metadata-Flavor response header: %q", flavor)
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(text)), nil
+}
+
+// checkProject reports whether the metadata server serves a project ID. Unlike Compute Engine instances,
+// Cloud Run instances have no zone.
+func (g *Gcp) checkProject(ctx context.Context, logger *zap.Logger) (types.Metadata, bool) {
+	for _, baseURL := range []string{metadataURL, metadataIPURL} {
+		logger.Debug(fmt.Sprintf("Checking %s project using url %s", identifier, baseURL+projectIDPath))
+
+		project, err := g.get(ctx, baseURL+projectIDPath, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error reading response: %s", err))
+			continue
+		}
+
+		if !projectIDPattern.MatchString(project) {
+			logger.Error(fmt.Sprintf("Unexpected project ID: %q", project))
+			return types.Metadata{}, false
+		}
+
+		return types.Metadata{Project: project}, true
+	}
+
+	return types.Metadata{}, false
+}
+
+func (g *Gcp) checkMetadataServer(ctx context.Context, logger *zap.Logger) (types.Metadata, bool) {
+	for _, baseURL := range []string{metadataURL, metadataIPURL} {
+		logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, baseURL+zonePath))
+
+		zone, err := g.get(ctx, baseURL+zonePath, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error reading response: %s", err))
+			continue
+		}
+
+		return g.checkMetadata(ctx, baseURL, zone, logger)
+	}
+
+	return types.Metadata{}, false
+}
+
+// checkMetadata validates the zone and fetches and validates the instance and project IDs
+// from the metadata server at baseURL.
+func (g *Gcp) checkMetadata(ctx context.Context, baseURL string, zone string, logger *zap.Logger) (types.Metadata, bool) {
+	match := zonePattern.FindStringSubmatch(zone)
+	if match == nil {
+		logger.Error(fmt.Sprintf("Unexpected zone in response: %q", zone))
+		return types.Metadata{}, false
+	}
+
+	instanceID, err := g.get(ctx, baseURL+instanceIDPath, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return types.Metadata{}, false
+	}
+	if !instanceIDPattern.MatchString(instanceID) {
+		logger.Error(fmt.Sprintf("Unexpected instance ID in response: %q", instanceID))
+		return types.Metadata{}, false
+	}
+
+	projectID, err := g.get(ctx, baseURL+projectIDPath, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return types.Metadata{}, false
+	}
+	if !projectIDPattern.MatchString(projectID) {
+		logger.Error(fmt.Sprintf("Unexpected project ID in response: %q", projectID))
+		return types.Metadata{}, false
+	}
+
+	return types.Metadata{
+		InstanceID: instanceID,
+		Region:     match[1],
+		Zone:       zone[strings.LastIndex(zone, "/")+1:],
+		Project:    projectID,
+	}, true
 }
 
 func (g *Gcp) checkVendorFile(file string, logger *zap.Logger) bool {
@@ -75,3 +361,238 @@ func (g *Gcp) checkVendorFile(file string, logger *zap.Logger) bool {
 
 	return strings.Contains(string(content), "Google")
 }
+
+func (g *Gcp) checkBiosVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s bios vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "Google"
+}
+
+func (g *Gcp) checkDiskModelFiles(pattern string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s disk model files %s", identifier, pattern))
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+		return false
+	}
+
+	for _, file := range files {
+		content, readErr := os.ReadFile(file)
+		if readErr != nil {
+			logger.Error(fmt.Sprintf("Error reading file: %s", readErr))
+			continue
+		}
+
+		if slices.Contains(diskModels, strings.TrimSpace(string(content))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Gcp) checkDiskLinks(pattern string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s disk links %s", identifier, pattern))
+
+	links, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+		return false
+	}
+
+	return len(links) > 0
+}
+
+func (g *Gcp) checkAgentConfigFiles(files []string, logger *zap.Logger) bool {
+	for _, file := range files {
+		logger.Debug(fmt.Sprintf("Checking %s guest agent config file %s", identifier, file))
+
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Gcp) checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s MAC address files %s", identifier, pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	vendor, ok := network.MatchMAC(identifier, addrs)
+	return vendor.Confidence, ok
+}
+
+// dnsMetadata returns the metadata in an internal DNS name of Compute Engine, [<instance>.][<zone>.]c.<project>.internal.
+func dnsMetadata(name string) (types.Metadata, bool) {
+	labels := strings.Split(name, ".")
+	n := len(labels)
+	if n < 3 || labels[n-1] != "internal" || labels[n-3] != "c" || !projectIDPattern.MatchString(labels[n-2]) {
+		return types.Metadata{}, false
+	}
+
+	metadata := types.Metadata{Project: labels[n-2]}
+	if n > 3 {
+		if match := zoneNamePattern.FindStringSubmatch(labels[n-4]); match != nil {
+			metadata.Region, metadata.Zone = match[1], labels[n-4]
+		}
+	}
+
+	return metadata, true
+}
+
+// checkResolvConfFile checks for the search domains of Compute Engine, google.internal and the project and zone
+// domains, which carry the project and zone.
+func (g *Gcp) checkResolvConfFile(file string, logger *zap.Logger) (types.Metadata, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s search domains in %s", identifier, file))
+
+	domains, err := network.ReadSearchDomains(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return types.Metadata{}, false
+	}
+
+	var metadata types.Metadata
+	found := false
+	for _, domain := range domains {
+		if m, ok := dnsMetadata(domain); ok && (!found || metadata.Zone == "") {
+			metadata, found = m, true
+		}
+		found = found || network.InDomain(domain, "google.internal")
+	}
+
+	return metadata, found
+}
+
+func (g *Gcp) checkHostnameFile(file string, logger *zap.Logger) (types.Metadata, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s hostname file %s", identifier, file))
+
+	hostname, err := network.ReadHostname(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return types.Metadata{}, false
+	}
+
+	return dnsMetadata(hostname)
+}
+
+func (g *Gcp) checkInterfaceAddresses(addrs []netip.Addr, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s interface addresses", identifier))
+
+	addr, ok := network.OwnedAddr(identifier, addrs)
+	if ok {
+		logger.Debug(fmt.Sprintf("Interface address %s is in a published %s range", addr, identifier))
+	}
+
+	return ok
+}
+
+// Attest requests an identity token for a fresh audience from the metadata server, and verifies that it is
+// signed by a key of the JSON Web Key Set read from the trust material attached to the context
+// (see package attest), or else fetched from Google, and issued to a Compute Engine instance.
+func (g *Gcp) Attest(ctx context.Context, logger *zap.Logger) bool {
+	// The audience is echoed in the signed token so that it can't be replayed.
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		logger.Error(fmt.Sprintf("Error generating audience: %s", err))
+		return false
+	}
+	audience := "clouddetect-" + hex.EncodeToString(nonce)
+
+	keys, err := attest.ReadJWKS(attest.Trust(ctx), "gcp/jwks.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		keys, err = g.getJWKS(ctx, logger)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading %s keys: %s", identifier, err))
+		return false
+	}
+
+	query := "?audience=" + url.QueryEscape(audience) + "&format=full"
+	for _, baseURL := range []string{metadataURL, metadataIPURL} {
+		logger.Debug(fmt.Sprintf("Verifying %s identity token using url %s", identifier, baseURL+identityPath))
+
+		token, getErr := g.get(ctx, baseURL+identityPath+query, logger)
+		if getErr != nil {
+			logger.Error(fmt.Sprintf("Error reading response: %s", getErr))
+			continue
+		}
+
+		return g.verifyIdentityToken(token, audience, keys, logger)
+	}
+
+	return false
+}
+
+// getJWKS returns the keys Google currently signs identity tokens with. They are fetched over HTTPS, so
+// unlike the metadata server, the response is authenticated.
+func (g *Gcp) getJWKS(ctx context.Context, logger *zap.Logger) (map[string]*rsa.PublicKey, error) {
+	logger.Debug(fmt.Sprintf("Fetching %s keys using url %s", identifier, jwksURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error response status code: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return attest.ParseJWKS(content)
+}
+
+func (g *Gcp) verifyIdentityToken(token string, audience string, keys map[string]*rsa.PublicKey, logger *zap.Logger) bool {
+	payload, err := attest.VerifyJWT(token, keys)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error verifying identity token: %s", err))
+		return false
+	}
+
+	claims := new(identityClaims)
+	if err = json.Unmarshal(payload, claims); err != nil {
+		logger.Error(fmt.Sprintf("Error decoding identity token: %s", err))
+		return false
+	}
+
+	switch {
+	case !slices.Contains(issuers, claims.Issuer):
+		logger.Error(fmt.Sprintf("Unexpected identity token issuer: %q", claims.Issuer))
+		return false
+	case claims.Audience != audience:
+		logger.Error(fmt.Sprintf("Unexpected identity token audience: %q", claims.Audience))
+		return false
+	case !time.Now().Before(time.Unix(claims.Expiry, 0)):
+		logger.Error(fmt.Sprintf("Identity token expired at %s", time.Unix(claims.Expiry, 0)))
+		return false
+	}
+
+	return instanceIDPattern.MatchString(claims.Google.ComputeEngine.InstanceID)
+}
@@ -2,14 +2,23 @@ package gcp
 
 import (
 	"context"
+	"crypto/rsa"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/attest"
+	"github.com/nikhil-prabhu/clouddetect/v2/attest/attesttest"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -39,8 +48,7 @@ func TestIdentify(t *testing.T) {
 		{
 			name: "Identify GCP via metadata server",
 			setupMocks: func() {
-				httpmock.Activate()
-				httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, ""))
+				registerMetadata(metadataURL, "projects/123456789/zones/us-central1-a", "4520031799277581759", "my-project")
 			},
 			expectedProvider: identifier,
 		},
@@ -53,15 +61,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMocks()
 
 			g := &Gcp{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go g.Identify(context.Background(), ch, logger)
+			go g.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -70,42 +78,188 @@ func TestIdentify(t *testing.T) {
 	}
 }
 
-func TestCheckMetadataServer(t *testing.T) {
-	httpmock.Activate()
-	defer httpmock.DeactivateAndReset()
+func TestIdentifyCloudRunService(t *testing.T) {
+	tests := []struct {
+		name               string
+		env                map[string]string
+		setupMocks         func()
+		expectedConfidence types.Confidence
+		expectedPlatform   types.Platform
+		expectedProject    string
+	}{
+		{
+			name: "Confirmed by the metadata server",
+			env:  map[string]string{"K_SERVICE": "hello"},
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL+projectIDPath, flavorResponder(http.StatusOK, "Google", "my-project"))
+			},
+			expectedConfidence: types.HighConfidence,
+			expectedPlatform:   types.CloudRun,
+			expectedProject:    "my-project",
+		},
+		{
+			name:               "Without metadata server",
+			env:                map[string]string{"K_SERVICE": "hello"},
+			setupMocks:         func() {},
+			expectedConfidence: types.LowConfidence,
+			expectedPlatform:   types.CloudRun,
+		},
+		{
+			name: "Knative service on Kubernetes",
+			env:  map[string]string{"K_SERVICE": "hello", "KUBERNETES_SERVICE_HOST": "10.96.0.1"},
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL+projectIDPath, flavorResponder(http.StatusOK, "Google", "my-project"))
+			},
+			expectedConfidence: types.NoConfidence,
+		},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			g := &Gcp{}
+			ch := make(chan types.Evidence, 1)
+			g.Identify(context.Background(), ch, t.TempDir(), zap.NewNop())
+
+			var result types.Evidence
+			select {
+			case result = <-ch:
+			default:
+			}
+
+			if result.Confidence != tt.expectedConfidence || result.Platform != tt.expectedPlatform || result.Metadata.Project != tt.expectedProject {
+				t.Errorf("Identify() = %+v; want %s confidence, platform %q and project %q", result, tt.expectedConfidence, tt.expectedPlatform, tt.expectedProject)
+			}
+		})
+	}
+}
+
+func flavorResponder(status int, flavor string, body string) httpmock.Responder {
+	return httpmock.NewStringResponder(status, body).HeaderSet(http.Header{"Metadata-Flavor": {flavor}})
+}
+
+// registerMetadata registers responders for the metadata entries checked by checkMetadataServer.
+func registerMetadata(baseURL string, zone string, instanceID string, projectID string) {
+	httpmock.RegisterResponder("GET", baseURL+zonePath, flavorResponder(http.StatusOK, "Google", zone))
+	httpmock.RegisterResponder("GET", baseURL+instanceIDPath, flavorResponder(http.StatusOK, "Google", instanceID))
+	httpmock.RegisterResponder("GET", baseURL+projectIDPath, flavorResponder(http.StatusOK, "Google", projectID))
+}
+
+func TestCheckMetadataServer(t *testing.T) {
 	tests := []struct {
-		name           string
-		responseStatus int
-		expectedResult bool
+		name             string
+		setupMocks       func()
+		expectedResult   bool
+		expectedMetadata types.Metadata
 	}{
 		{
-			name:           "Successful metadata response",
-			responseStatus: http.StatusOK,
+			name: "Successful metadata response",
+			setupMocks: func() {
+				registerMetadata(metadataURL, "projects/123456789/zones/europe-west4-b", "4520031799277581759", "my-project")
+			},
 			expectedResult: true,
+			expectedMetadata: types.Metadata{
+				InstanceID: "4520031799277581759",
+				Region:     "europe-west4",
+				Zone:       "europe-west4-b",
+				Project:    "my-project",
+			},
+		},
+		{
+			name: "Domain-scoped project",
+			setupMocks: func() {
+				registerMetadata(metadataURL, "projects/123456789/zones/us-east1-c", "4520031799277581759", "example.com:my-project")
+			},
+			expectedResult: true,
+			expectedMetadata: types.Metadata{
+				InstanceID: "4520031799277581759",
+				Region:     "us-east1",
+				Zone:       "us-east1-c",
+				Project:    "example.com:my-project",
+			},
+		},
+		{
+			name: "Non-OK status code",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL+zonePath, flavorResponder(http.StatusInternalServerError, "Google", ""))
+			},
+			expectedResult: false,
 		},
 		{
-			name:           "Non-OK status code",
-			responseStatus: http.StatusInternalServerError,
+			name: "Missing Metadata-Flavor header",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL+zonePath, httpmock.NewStringResponder(http.StatusOK, "projects/123456789/zones/us-central1-a"))
+			},
+			expectedResult: false,
+		},
+		{
+			name: "GCP-compatible metadata server",
+			setupMocks: func() {
+				registerMetadata(metadataURL, "projects/b1g2h3j4k5l6m7n8p9q0/zones/ru-central1-a", "fhm0b28lgfp4tkoa3jl6", "b1g2h3j4k5l6m7n8p9q0")
+			},
+			expectedResult: false,
+		},
+		{
+			name: "Unparseable instance ID",
+			setupMocks: func() {
+				registerMetadata(metadataURL, "projects/123456789/zones/us-central1-a", "<html><body>Sign in to continue</body></html>", "my-project")
+			},
+			expectedResult: false,
+		},
+		{
+			name: "Unparseable project ID",
+			setupMocks: func() {
+				registerMetadata(metadataURL, "projects/123456789/zones/us-central1-a", "4520031799277581759", "")
+			},
 			expectedResult: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(tt.responseStatus, ""))
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
 
 			g := &Gcp{}
 			logger := zap.NewNop()
-			result := g.checkMetadataServer(context.Background(), logger)
+			metadata, result := g.checkMetadataServer(context.Background(), logger)
 
 			if result != tt.expectedResult {
 				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
 			}
+			if metadata != tt.expectedMetadata {
+				t.Errorf("checkMetadataServer() metadata = %+v; want %+v", metadata, tt.expectedMetadata)
+			}
 		})
 	}
 }
 
+func TestCheckMetadataServerFallsBackToIP(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", metadataURL+zonePath, httpmock.NewErrorResponder(&net.DNSError{Err: "no such host", Name: "metadata.google.internal", IsNotFound: true}))
+	registerMetadata(metadataIPURL, "projects/123456789/zones/asia-south1-c", "4520031799277581759", "my-project")
+
+	g := &Gcp{}
+	logger := zap.NewNop()
+	metadata, result := g.checkMetadataServer(context.Background(), logger)
+
+	want := types.Metadata{InstanceID: "4520031799277581759", Region: "asia-south1", Zone: "asia-south1-c", Project: "my-project"}
+	if !result || metadata != want {
+		t.Errorf("checkMetadataServer() = %+v, %v; want %+v, true", metadata, result, want)
+	}
+}
+
 func TestCheckVendorFile(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -144,3 +298,503 @@ func TestCheckVendorFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckEnvironment(t *testing.T) {
+	tests := []struct {
+		name               string
+		env                map[string]string
+		expectedPlatform   types.Platform
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name: "Cloud Run service",
+			env: map[string]string{
+				"K_SERVICE":  "hello",
+				"K_REVISION": "hello-00001-abc",
+			},
+			expectedPlatform:   types.CloudRun,
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "Knative service on Kubernetes",
+			env: map[string]string{
+				"K_SERVICE":               "hello",
+				"K_REVISION":              "hello-00001",
+				"KUBERNETES_SERVICE_HOST": "10.96.0.1",
+			},
+			expectedPlatform: types.NoPlatform,
+			expectedResult:   false,
+		},
+		{
+			name: "Cloud Run job",
+			env: map[string]string{
+				"CLOUD_RUN_JOB": "nightly",
+			},
+			expectedPlatform:   types.CloudRun,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "Cloud Functions",
+			env: map[string]string{
+				"K_SERVICE":       "hello",
+				"FUNCTION_TARGET": "HelloWorld",
+			},
+			expectedPlatform:   types.CloudFunctions,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "Functions Framework",
+			env: map[string]string{
+				"FUNCTION_TARGET": "HelloWorld",
+			},
+			expectedPlatform:   types.CloudFunctions,
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name: "App Engine",
+			env: map[string]string{
+				"GAE_APPLICATION": "s~my-project",
+			},
+			expectedPlatform:   types.AppEngine,
+			expectedConfidence: types.MediumConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:             "No managed runtime",
+			env:              map[string]string{},
+			expectedPlatform: types.NoPlatform,
+			expectedResult:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBERNETES_SERVICE_HOST", "")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			platform, confidence, result := g.checkEnvironment(logger)
+
+			if result != tt.expectedResult || platform != tt.expectedPlatform || confidence != tt.expectedConfidence {
+				t.Errorf("checkEnvironment() = %v, %v, %v; want %v, %v, %v", platform, confidence, result, tt.expectedPlatform, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckBiosVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Google BIOS",
+			fileContent:    "Google\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other BIOS",
+			fileContent:    "SeaBIOS\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			result := g.checkBiosVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkBiosVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckDiskModelFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		models         map[string]string
+		expectedResult bool
+	}{
+		{
+			name:           "SCSI Persistent Disk",
+			models:         map[string]string{"sda": "PersistentDisk  \n"},
+			expectedResult: true,
+		},
+		{
+			name:           "NVMe Persistent Disk",
+			models:         map[string]string{"nvme0n1": "nvme_card-pd\n"},
+			expectedResult: true,
+		},
+		{
+			name:           "Other disk",
+			models:         map[string]string{"vda": "QEMU HARDDISK\n"},
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for disk, model := range tt.models {
+				device := filepath.Join(dir, disk, "device")
+				if err := os.MkdirAll(device, 0o755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(device, "model"), []byte(model), 0o644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			result := g.checkDiskModelFiles(filepath.Join(dir, "*", "device", "model"), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkDiskModelFiles() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckDiskLinks(t *testing.T) {
+	dir := t.TempDir()
+	g := &Gcp{}
+	logger := zap.NewNop()
+
+	if g.checkDiskLinks(filepath.Join(dir, "google-*"), logger) {
+		t.Error("Expected checkDiskLinks() to return false without google- disk links")
+	}
+
+	if err := os.Symlink("../../nvme0n1", filepath.Join(dir, "google-persistent-disk-0")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if !g.checkDiskLinks(filepath.Join(dir, "google-*"), logger) {
+		t.Error("Expected checkDiskLinks() to return true with google- disk links")
+	}
+}
+
+func TestCheckAgentConfigFiles(t *testing.T) {
+	tmpFile := createTempFile(t, "[InstanceSetup]\nset_host_keys = true\n")
+	defer func(name string) {
+		err := os.Remove(name)
+		if err != nil {
+			t.Fatalf("Failed to remove temp file: %v", err)
+		}
+	}(tmpFile)
+
+	g := &Gcp{}
+	logger := zap.NewNop()
+
+	if !g.checkAgentConfigFiles([]string{"/path/to/nonexistent/file", tmpFile}, logger) {
+		t.Error("Expected checkAgentConfigFiles() to return true with a guest agent config file")
+	}
+
+	if g.checkAgentConfigFiles([]string{"/path/to/nonexistent/file"}, logger) {
+		t.Error("Expected checkAgentConfigFiles() to return false without guest agent config files")
+	}
+}
+
+func TestCheckResolvConfFile(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileContent      string
+		expectedMetadata types.Metadata
+		expectedResult   bool
+	}{
+		{
+			name:             "Zonal search domains",
+			fileContent:      "nameserver 169.254.169.254\nsearch c.my-project.internal us-central1-a.c.my-project.internal google.internal\n",
+			expectedMetadata: types.Metadata{Region: "us-central1", Zone: "us-central1-a", Project: "my-project"},
+			expectedResult:   true,
+		},
+		{
+			name:             "Global search domains",
+			fileContent:      "search c.my-project.internal google.internal\n",
+			expectedMetadata: types.Metadata{Project: "my-project"},
+			expectedResult:   true,
+		},
+		{
+			name:           "Google domain only",
+			fileContent:    "search google.internal\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other domain",
+			fileContent:    "search c.example.com\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			metadata, result := g.checkResolvConfFile(tmpFile, logger)
+
+			if metadata != tt.expectedMetadata || result != tt.expectedResult {
+				t.Errorf("checkResolvConfFile() = %+v, %v; want %+v, %v", metadata, result, tt.expectedMetadata, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckHostnameFile(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileContent      string
+		expectedMetadata types.Metadata
+		expectedResult   bool
+	}{
+		{
+			name:             "Zonal DNS name",
+			fileContent:      "instance-1.europe-west4-b.c.my-project.internal\n",
+			expectedMetadata: types.Metadata{Region: "europe-west4", Zone: "europe-west4-b", Project: "my-project"},
+			expectedResult:   true,
+		},
+		{
+			name:             "Global DNS name",
+			fileContent:      "instance-1.c.my-project.internal\n",
+			expectedMetadata: types.Metadata{Project: "my-project"},
+			expectedResult:   true,
+		},
+		{
+			name:           "Short hostname",
+			fileContent:    "instance-1\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			metadata, result := g.checkHostnameFile(tmpFile, logger)
+
+			if metadata != tt.expectedMetadata || result != tt.expectedResult {
+				t.Errorf("checkHostnameFile() = %+v, %v; want %+v, %v", metadata, result, tt.expectedMetadata, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckInterfaceAddresses(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("2600:1900:4010::/44"), Provider: types.Gcp, Region: "europe-west1"})
+	iprange.SetDefault(&table)
+
+	g := &Gcp{}
+	logger := zap.NewNop()
+
+	if !g.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.128.0.2"), netip.MustParseAddr("2600:1900:4010::2")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return true with an address in a published range")
+	}
+
+	if g.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.128.0.2")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return false without an address in a published range")
+	}
+}
+
+// identityResponder answers identity token requests with a token signed by key, whose claims are
+// set by claims from the audience of the request.
+func identityResponder(t *testing.T, key *rsa.PrivateKey, claims func(audience string) map[string]any) httpmock.Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		token := attesttest.SignJWT(t, key, "key-1", claims(req.URL.Query().Get("audience")))
+		return flavorResponder(http.StatusOK, "Google", token)(req)
+	}
+}
+
+func TestAttest(t *testing.T) {
+	key := attesttest.NewKey(t)
+	otherKey := attesttest.NewKey(t)
+	jwks := attesttest.JWKS(t, map[string]*rsa.PrivateKey{"key-1": key})
+	trust := fstest.MapFS{"gcp/jwks.json": {Data: jwks}}
+
+	validClaims := func(audience string) map[string]any {
+		return map[string]any{
+			"iss": "https://accounts.google.com",
+			"aud": audience,
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"google": map[string]any{
+				"compute_engine": map[string]any{
+					"instance_id": "4520031799277581759",
+					"project_id":  "my-project",
+					"zone":        "europe-west4-b",
+				},
+			},
+		}
+	}
+	withClaim := func(name string, value any) func(string) map[string]any {
+		return func(audience string) map[string]any {
+			claims := validClaims(audience)
+			claims[name] = value
+			return claims
+		}
+	}
+
+	tests := []struct {
+		name           string
+		trust          fstest.MapFS
+		baseURL        string
+		responder      httpmock.Responder
+		jwksResponder  httpmock.Responder
+		expectedResult bool
+	}{
+		{
+			name:           "Valid token",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, validClaims),
+			expectedResult: true,
+		},
+		{
+			name:           "Valid token from metadata server IP",
+			trust:          trust,
+			baseURL:        metadataIPURL,
+			responder:      identityResponder(t, key, validClaims),
+			expectedResult: true,
+		},
+		{
+			name:           "Token signed by another key",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, otherKey, validClaims),
+			expectedResult: false,
+		},
+		{
+			name:           "Token for another audience",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, withClaim("aud", "https://example.com")),
+			expectedResult: false,
+		},
+		{
+			name:           "Token from another issuer",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, withClaim("iss", "https://example.com")),
+			expectedResult: false,
+		},
+		{
+			name:           "Expired token",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, withClaim("exp", time.Now().Add(-time.Minute).Unix())),
+			expectedResult: false,
+		},
+		{
+			name:           "Token without Compute Engine claims",
+			trust:          trust,
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, withClaim("google", map[string]any{})),
+			expectedResult: false,
+		},
+		{
+			name:           "Keys fetched from Google",
+			trust:          fstest.MapFS{},
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, validClaims),
+			jwksResponder:  httpmock.NewBytesResponder(http.StatusOK, jwks),
+			expectedResult: true,
+		},
+		{
+			name:           "No keys",
+			trust:          fstest.MapFS{},
+			baseURL:        metadataURL,
+			responder:      identityResponder(t, key, validClaims),
+			jwksResponder:  httpmock.NewStringResponder(http.StatusServiceUnavailable, ""),
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			httpmock.RegisterResponder("GET", tt.baseURL+identityPath, tt.responder)
+			if tt.jwksResponder != nil {
+				httpmock.RegisterResponder("GET", jwksURL, tt.jwksResponder)
+			}
+
+			g := &Gcp{}
+			ctx := attest.WithTrust(context.Background(), tt.trust)
+
+			if result := g.Attest(ctx, zap.NewNop()); result != tt.expectedResult {
+				t.Errorf("Attest() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMACAddressFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		address            string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "Compute Engine MAC address",
+			address:            "42:01:0a:80:00:02",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other MAC address",
+			address:        "00:0d:3a:12:34:56",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testutil.CreateRoot(t, map[string]string{"/eth0/address": tt.address + "\n", "/eth0/device/uevent": ""})
+
+			g := &Gcp{}
+			logger := zap.NewNop()
+			confidence, result := g.checkMACAddressFiles(filepath.Join(dir, "*", "address"), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMACAddressFiles() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Package hyperv implements the Microsoft Hyper-V private virtualization platform detection.
+//
+// Azure virtual machines run on Hyper-V too, and are told apart by the chassis asset tag Azure sets.
+package hyperv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/azure"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	vendorFile      = "/sys/class/dmi/id/sys_vendor"
+	productNameFile = "/sys/class/dmi/id/product_name"
+	identifier      = types.Hyperv
+)
+
+type Hyperv struct{}
+
+func (h *Hyperv) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (h *Hyperv) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	vendor, productName, chassisAsset := filepath.Join(root, vendorFile), filepath.Join(root, productNameFile), filepath.Join(root, azure.ChassisAssetFile)
+	if probe.File(ctx, identifier, "sys_vendor file", vendor, func() bool { return h.checkVendorFile(vendor, logger) }) &&
+		probe.File(ctx, identifier, "product_name file", productName, func() bool { return h.checkProductNameFile(productName, logger) }) &&
+		!probe.File(ctx, identifier, "azure chassis_asset_tag file", chassisAsset, func() bool { return (&azure.Azure{}).CheckChassisAssetFile(chassisAsset, logger) }) {
+		ch <- types.Evidence{Provider: h.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	var confidence types.Confidence
+	if pattern := filepath.Join(root, network.MACAddressFiles); probe.Run(ctx, identifier, "mac address files", pattern, func() (ok bool) {
+		confidence, ok = h.checkMACAddressFiles(pattern, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: h.Identifier(), Confidence: confidence}
+		return
+	}
+}
+
+func (h *Hyperv) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "Microsoft Corporation"
+}
+
+func (h *Hyperv) checkProductNameFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s product name file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "Virtual Machine"
+}
+
+func (h *Hyperv) checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s MAC address files %s", identifier, pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	vendor, ok := network.MatchMAC(identifier, addrs)
+	return vendor.Confidence, ok
+}
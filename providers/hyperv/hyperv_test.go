@@ -0,0 +1,214 @@
+package hyperv
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	h := &Hyperv{}
+	if h.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", h.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify Hyper-V via DMI",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":        "Microsoft Corporation\n",
+				"sys/class/dmi/id/product_name":      "Virtual Machine\n",
+				"sys/class/dmi/id/chassis_asset_tag": "5471-3318-1037-7021-8715-0437-93\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Azure virtual machine",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":        "Microsoft Corporation\n",
+				"sys/class/dmi/id/product_name":      "Virtual Machine\n",
+				"sys/class/dmi/id/chassis_asset_tag": "7783-7084-3265-9085-8269-3286-77\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+		{
+			name: "Microsoft hardware",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "Microsoft Corporation\n",
+				"sys/class/dmi/id/product_name": "Surface Pro 9\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.CreateRoot(t, tt.files)
+
+			h := &Hyperv{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			h.Identify(context.Background(), ch, root, logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			default:
+				if tt.expectedProvider != types.Unknown {
+					t.Error("Identify() reported no evidence")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Microsoft vendor",
+			fileContent:    "Microsoft Corporation\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "VMware, Inc.",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			h := &Hyperv{}
+			logger := zap.NewNop()
+			result := h.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckProductNameFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Virtual machine product",
+			fileContent:    "Virtual Machine\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other product",
+			fileContent:    "Surface Pro 9",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			h := &Hyperv{}
+			logger := zap.NewNop()
+			result := h.checkProductNameFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkProductNameFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMACAddressFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		address            string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "Hyper-V MAC address",
+			address:            "00:15:5d:aa:bb:cc",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other MAC address",
+			address:        "00:50:56:aa:bb:cc",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "eth0", "device"), 0o755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "eth0", "address"), []byte(tt.address+"\n"), 0o644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			h := &Hyperv{}
+			logger := zap.NewNop()
+			confidence, result := h.checkMACAddressFiles(filepath.Join(dir, "*", "address"), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMACAddressFiles() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Package nutanix implements the Nutanix AHV private virtualization platform detection.
+package nutanix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	vendorFile      = "/sys/class/dmi/id/sys_vendor"
+	productNameFile = "/sys/class/dmi/id/product_name"
+	identifier      = types.Nutanix
+)
+
+type Nutanix struct{}
+
+func (n *Nutanix) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (n *Nutanix) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return n.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: n.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, productNameFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return n.checkProductNameFile(file, logger) }) {
+		ch <- types.Evidence{Provider: n.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+func (n *Nutanix) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "Nutanix"
+}
+
+func (n *Nutanix) checkProductNameFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s product name file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(string(content), "Nutanix AHV")
+}
@@ -0,0 +1,168 @@
+package nutanix
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	n := &Nutanix{}
+	if n.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", n.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify Nutanix via vendor file",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "Nutanix\n",
+				"sys/class/dmi/id/product_name": "AHV\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Identify Nutanix via product name file",
+			files: map[string]string{
+				"sys/class/dmi/id/product_name": "Nutanix AHV\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Other vendor",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor": "QEMU\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.CreateRoot(t, tt.files)
+
+			n := &Nutanix{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			n.Identify(context.Background(), ch, root, logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			default:
+				if tt.expectedProvider != types.Unknown {
+					t.Error("Identify() reported no evidence")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Nutanix vendor",
+			fileContent:    "Nutanix\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "QEMU",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			n := &Nutanix{}
+			logger := zap.NewNop()
+			result := n.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckProductNameFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Nutanix AHV product",
+			fileContent:    "Nutanix AHV\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other product",
+			fileContent:    "Standard PC (i440FX + PIIX, 1996)",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			n := &Nutanix{}
+			logger := zap.NewNop()
+			result := n.checkProductNameFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkProductNameFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -7,22 +7,32 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
 const (
-	metadataURL string = "http://169.254.169.254/opc/v1/instance/metadata"
+	metadataURL string = "http://169.254.169.254/opc/v2/instance/"
 	vendorFile         = "/sys/class/dmi/id/chassis_asset_tag"
 	identifier         = types.Oci
 )
 
+// dnsDomains are the domains of the internet and VCN resolver, e.g. <subnet>.<vcn>.oraclevcn.com.
+var dnsDomains = []string{"oraclevcn.com"}
+
 type metadataResponse struct {
-	OkeTm string `json:"oke_tm"`
+	ID                  string `json:"id"`
+	CanonicalRegionName string `json:"canonicalRegionName"`
+	AvailabilityDomain  string `json:"availabilityDomain"`
 }
 
 type Oci struct{}
@@ -31,32 +41,76 @@ func (o *Oci) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (o *Oci) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if o.checkMetadataServer(ctx, logger) {
-		ch <- o.Identifier()
+func (o *Oci) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	var metadata *metadataResponse
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) (ok bool) {
+		metadata, ok = o.checkMetadataServer(ctx, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   o.Identifier(),
+			Confidence: types.HighConfidence,
+			Metadata: types.Metadata{
+				InstanceID: metadata.ID,
+				Region:     metadata.CanonicalRegionName,
+				Zone:       metadata.AvailabilityDomain,
+			},
+		}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "chassis_asset_tag file", file, func() bool { return o.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	var confidence types.Confidence
+	if pattern := filepath.Join(root, network.MACAddressFiles); probe.Run(ctx, identifier, "mac address files", pattern, func() (ok bool) {
+		confidence, ok = o.checkMACAddressFiles(pattern, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: confidence}
 		return
 	}
 
-	if o.checkVendorFile(vendorFile, logger) {
-		ch <- o.Identifier()
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// The network configuration is only a hint, as any host can use the same names and addresses.
+	if file := filepath.Join(root, network.ResolvConfFile); probe.File(ctx, identifier, "resolv.conf search domains", file, func() bool { return o.checkResolvConfFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.LowConfidence}
+		return
+	}
+
+	addrs := network.InterfaceAddrs(root, logger)
+	if probe.Run(ctx, identifier, "interface addresses", "interfaces", func() bool { return o.checkInterfaceAddresses(addrs, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.LowConfidence}
 		return
 	}
 }
 
-func (o *Oci) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
+func (o *Oci) checkMetadataServer(ctx context.Context, logger *zap.Logger) (*metadataResponse, bool) {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
-		return false
+		return nil, false
 	}
+	req.Header.Add("Authorization", "Bearer Oracle")
 
 	resp, err := client.Do(req)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error reading response: %s", err))
-		return false
+		return nil, false
 	}
 	defer func(Body io.ReadCloser) {
 		closeErr := Body.Close()
@@ -67,16 +121,16 @@ func (o *Oci) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
-		return false
+		return nil, false
 	}
 
 	metadata := new(metadataResponse)
 	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
 		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
-		return false
+		return nil, false
 	}
 
-	return strings.Contains(metadata.OkeTm, "oke")
+	return metadata, strings.HasPrefix(metadata.ID, "ocid1.instance.")
 }
 
 func (o *Oci) checkVendorFile(file string, logger *zap.Logger) bool {
@@ -90,3 +144,46 @@ func (o *Oci) checkVendorFile(file string, logger *zap.Logger) bool {
 
 	return strings.Contains(string(content), "OracleCloud")
 }
+
+func (o *Oci) checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s MAC address files %s", identifier, pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	vendor, ok := network.MatchMAC(identifier, addrs)
+	return vendor.Confidence, ok
+}
+
+func (o *Oci) checkResolvConfFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s search domains in %s", identifier, file))
+
+	domains, err := network.ReadSearchDomains(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	for _, domain := range domains {
+		for _, d := range dnsDomains {
+			if network.InDomain(domain, d) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (o *Oci) checkInterfaceAddresses(addrs []netip.Addr, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s interface addresses", identifier))
+
+	addr, ok := network.OwnedAddr(identifier, addrs)
+	if ok {
+		logger.Debug(fmt.Sprintf("Interface address %s is in a published %s range", addr, identifier))
+	}
+
+	return ok
+}
@@ -3,13 +3,17 @@ package oci
 import (
 	"context"
 	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/iprange"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -41,7 +45,7 @@ func TestIdentify(t *testing.T) {
 			setupMocks: func() {
 				httpmock.Activate()
 				httpmock.RegisterResponder("GET", metadataURL, httpmock.NewJsonResponderOrPanic(200, metadataResponse{
-					OkeTm: "oke-instance",
+					ID: "ocid1.instance.oc1.phx.abyhqljt",
 				}))
 			},
 			expectedProvider: identifier,
@@ -55,15 +59,15 @@ func TestIdentify(t *testing.T) {
 			tt.setupMocks()
 
 			o := &Oci{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go o.Identify(context.Background(), ch, logger)
+			go o.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -83,15 +87,17 @@ func TestCheckMetadataServer(t *testing.T) {
 		expectedResult bool
 	}{
 		{
-			name:           "Successful metadata response with OKE_TM",
+			name:           "Successful metadata response",
 			responseStatus: http.StatusOK,
 			responseBody: &metadataResponse{
-				OkeTm: "oke-instance",
+				ID:                  "ocid1.instance.oc1.phx.abyhqljt",
+				CanonicalRegionName: "us-phoenix-1",
+				AvailabilityDomain:  "EMIr:PHX-AD-1",
 			},
 			expectedResult: true,
 		},
 		{
-			name:           "Metadata response without OKE_TM",
+			name:           "Metadata response without instance ID",
 			responseStatus: http.StatusOK,
 			responseBody:   &metadataResponse{},
 			expectedResult: false,
@@ -110,7 +116,7 @@ func TestCheckMetadataServer(t *testing.T) {
 
 			o := &Oci{}
 			logger := zap.NewNop()
-			result := o.checkMetadataServer(context.Background(), logger)
+			_, result := o.checkMetadataServer(context.Background(), logger)
 
 			if result != tt.expectedResult {
 				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
@@ -157,3 +163,96 @@ func TestCheckVendorFile(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckResolvConfFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "VCN domain",
+			fileContent:    "search vcn1.oraclevcn.com subnet1.vcn1.oraclevcn.com\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other domain",
+			fileContent:    "nameserver 10.0.0.2\nsearch corp.example.com\n",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			o := &Oci{}
+			logger := zap.NewNop()
+			result := o.checkResolvConfFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkResolvConfFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckInterfaceAddresses(t *testing.T) {
+	t.Cleanup(func() { iprange.SetDefault(nil) })
+
+	var table iprange.Table
+	table.Insert(iprange.Range{Prefix: netip.MustParsePrefix("129.146.0.0/21"), Provider: identifier})
+	iprange.SetDefault(&table)
+
+	o := &Oci{}
+	logger := zap.NewNop()
+
+	if !o.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("129.146.0.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return true with an address in a published range")
+	}
+
+	if o.checkInterfaceAddresses([]netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("198.51.100.10")}, logger) {
+		t.Error("Expected checkInterfaceAddresses() to return false without an address in a published range")
+	}
+}
+
+func TestCheckMACAddressFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		address            string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "Oracle Cloud MAC address",
+			address:            "02:00:17:01:02:03",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other MAC address",
+			address:        "02:42:ac:11:00:02",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := testutil.CreateRoot(t, map[string]string{"/eth0/address": tt.address + "\n", "/eth0/device/uevent": ""})
+
+			o := &Oci{}
+			logger := zap.NewNop()
+			confidence, result := o.checkMACAddressFiles(filepath.Join(dir, "*", "address"), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMACAddressFiles() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
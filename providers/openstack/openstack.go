@@ -7,11 +7,16 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/ovh"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -25,35 +30,77 @@ const (
 var (
 	productNames     = []string{"OpenStack Nova", "OpenStack Compute"}
 	chassisAssetTags = []string{"HUAWEICLOUD", "OpenTelekomCloud", "SAP CCloud VM", "OpenStack Nova", "OpenStack Compute"}
+
+	// specializations are OpenStack-based providers that take precedence over generic OpenStack detection.
+	specializations = []specialization{&ovh.Ovh{}}
 )
 
+// specialization is an OpenStack-based provider that can report whether it matches the host.
+type specialization interface {
+	Identifier() types.ProviderId
+	Matches(context.Context, string, *zap.Logger) bool
+}
+
 type OpenStack struct{}
 
 func (o *OpenStack) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (o *OpenStack) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if o.checkMetadataServer(ctx, logger) {
-		ch <- o.Identifier()
+func (o *OpenStack) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if o.deferToSpecialization(ctx, root, logger) {
+		return
+	}
+
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
 		return
 	}
 
-	if o.checkProductNameFile(productNameFile, logger) {
-		ch <- o.Identifier()
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return o.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.HighConfidence}
 		return
 	}
 
-	if o.checkChassisAssetTagFile(chassisAssetTagFile, logger) {
-		ch <- o.Identifier()
+	if file := filepath.Join(root, productNameFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return o.checkProductNameFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
 		return
 	}
+
+	if file := filepath.Join(root, chassisAssetTagFile); probe.File(ctx, identifier, "chassis_asset_tag file", file, func() bool { return o.checkChassisAssetTagFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+func (o *OpenStack) deferToSpecialization(ctx context.Context, root string, logger *zap.Logger) bool {
+	for _, s := range specializations {
+		// An excluded specialization isn't checked, so its hosts are reported as OpenStack instead.
+		if !detection.Enabled(ctx, s.Identifier()) {
+			continue
+		}
+
+		// The specialization's checks run once per detection and are recorded as its own.
+		if probe.Run(ctx, identifier, fmt.Sprintf("%s specialization", s.Identifier()), string(s.Identifier()), func() bool {
+			return s.Matches(ctx, root, logger)
+		}) {
+			logger.Debug(fmt.Sprintf("Deferring %s detection to %s", identifier, s.Identifier()))
+			return true
+		}
+	}
+
+	return false
 }
 
 func (o *OpenStack) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
@@ -10,9 +10,14 @@ import (
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/providers/ovh"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
+const vendorDataURL = "http://169.254.169.254/openstack/latest/vendor_data.json"
+
 func createTempFile(t *testing.T, content string) string {
 	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
 	if err != nil {
@@ -52,15 +57,15 @@ func TestIdentify(t *testing.T) {
 			defer httpmock.DeactivateAndReset()
 
 			o := &OpenStack{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go o.Identify(context.Background(), ch, logger)
+			go o.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
@@ -69,6 +74,83 @@ func TestIdentify(t *testing.T) {
 	}
 }
 
+func TestIdentifyDefersToSpecialization(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// OVHcloud serves both the generic OpenStack metadata and its own vendor data.
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, ""))
+	httpmock.RegisterResponder("GET", vendorDataURL, httpmock.NewStringResponder(200, `{"ovh": {}}`))
+
+	o := &OpenStack{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+
+	o.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+	select {
+	case result := <-ch:
+		t.Errorf("Identify() = %v; want no result", result.Provider)
+	default:
+	}
+}
+
+func TestIdentifyWithoutSpecialization(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, ""))
+	httpmock.RegisterResponder("GET", vendorDataURL, httpmock.NewStringResponder(200, `{"ovh": {}}`))
+
+	// OVHcloud is excluded from the detection, so the host is reported as OpenStack.
+	run := detection.NewRun(func(id types.ProviderId) bool { return id != types.Ovh })
+	ctx := detection.WithRun(context.Background(), run)
+
+	o := &OpenStack{}
+	ch := make(chan types.Evidence, 1)
+	o.Identify(ctx, ch, testutil.CreateRoot(t, nil), zap.NewNop())
+
+	select {
+	case result := <-ch:
+		if result.Provider != identifier {
+			t.Errorf("Identify() = %v; want %v", result.Provider, identifier)
+		}
+	default:
+		t.Error("Identify() = no result; want openstack")
+	}
+
+	if calls := httpmock.GetCallCountInfo()["GET "+vendorDataURL]; calls != 0 {
+		t.Errorf("Identify() requested the vendor data %d times; want 0", calls)
+	}
+}
+
+func TestIdentifySharesSpecializationChecks(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(200, ""))
+	httpmock.RegisterResponder("GET", vendorDataURL, httpmock.NewStringResponder(200, `{"ovh": {}}`))
+
+	run := detection.NewRun(func(types.ProviderId) bool { return true })
+	ctx := detection.WithRun(context.Background(), run)
+
+	ch := make(chan types.Evidence, 2)
+	root := testutil.CreateRoot(t, nil)
+	(&ovh.Ovh{}).Identify(ctx, ch, root, zap.NewNop())
+	(&OpenStack{}).Identify(ctx, ch, root, zap.NewNop())
+
+	if result := <-ch; result.Provider != types.Ovh {
+		t.Errorf("Identify() = %v; want %v", result.Provider, types.Ovh)
+	}
+	if len(ch) != 0 {
+		t.Errorf("OpenStack Identify() = %v; want no result", (<-ch).Provider)
+	}
+
+	if calls := httpmock.GetCallCountInfo()["GET "+vendorDataURL]; calls != 1 {
+		t.Errorf("vendor data requested %d times; want 1", calls)
+	}
+}
+
 func TestCheckMetadataServer(t *testing.T) {
 	httpmock.Activate()
 	defer httpmock.DeactivateAndReset()
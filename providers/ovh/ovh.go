@@ -0,0 +1,176 @@
+// Package ovh implements the OVHcloud Public Cloud provider detection.
+package ovh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/detection"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	metadataURL     string = "http://169.254.169.254/openstack/latest/meta_data.json"
+	vendorDataURL   string = "http://169.254.169.254/openstack/latest/vendor_data.json"
+	vendorFile             = "/sys/class/dmi/id/sys_vendor"
+	productNameFile        = "/sys/class/dmi/id/product_name"
+	identifier             = types.Ovh
+)
+
+// availabilityZonePattern matches the OVHcloud region naming scheme (e.g. GRA11, BHS5, eu-west-par-a).
+var availabilityZonePattern = regexp.MustCompile(`(?i)^((gra|sbg|bhs|waw|de|uk|rbx|lim|syd|sgp|ynm)\d+|[a-z]{2}-[a-z]+-[a-z]{3}-[a-c])$`)
+
+type metadataResponse struct {
+	AvailabilityZone string `json:"availability_zone"`
+}
+
+type Ovh struct{}
+
+func (o *Ovh) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (o *Ovh) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if confidence := o.match(ctx, root, logger); confidence != types.NoConfidence {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: confidence}
+		return
+	}
+}
+
+// Matches reports whether the host is an OVHcloud Public Cloud instance.
+//
+// OVHcloud Public Cloud is built on OpenStack, so the OpenStack provider uses this to defer to OVHcloud. The checks
+// run once per detection, whichever provider asks first.
+func (o *Ovh) Matches(ctx context.Context, root string, logger *zap.Logger) bool {
+	return o.match(ctx, root, logger) != types.NoConfidence
+}
+
+func (o *Ovh) match(ctx context.Context, root string, logger *zap.Logger) types.Confidence {
+	return detection.Match(ctx, identifier, func() types.Confidence { return o.check(ctx, root, logger) })
+}
+
+func (o *Ovh) check(ctx context.Context, root string, logger *zap.Logger) types.Confidence {
+	if probe.Request(ctx, identifier, "vendor data", vendorDataURL, func(ctx context.Context) bool { return o.checkVendorData(ctx, logger) }) {
+		return types.HighConfidence
+	}
+
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return o.checkMetadataServer(ctx, logger) }) {
+		return types.HighConfidence
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return o.checkVendorFile(file, logger) }) {
+		return types.MediumConfidence
+	}
+
+	if file := filepath.Join(root, productNameFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return o.checkProductNameFile(file, logger) }) {
+		return types.MediumConfidence
+	}
+
+	return types.NoConfidence
+}
+
+func (o *Ovh) checkVendorData(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor data using url %s", identifier, vendorDataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", vendorDataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return false
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response body: %s", err))
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(string(text)), "ovh")
+}
+
+func (o *Ovh) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return false
+	}
+
+	metadata := new(metadataResponse)
+	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return false
+	}
+
+	return availabilityZonePattern.MatchString(strings.TrimSpace(metadata.AvailabilityZone))
+}
+
+func (o *Ovh) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(strings.ToUpper(string(content)), "OVH")
+}
+
+func (o *Ovh) checkProductNameFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s product name using file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(strings.ToUpper(string(content)), "OVH")
+}
@@ -0,0 +1,253 @@
+package ovh
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	o := &Ovh{}
+	if o.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", o.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupMocks       func()
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify OVHcloud via vendor data",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", vendorDataURL, httpmock.NewStringResponder(200, `{"ovh": {}}`))
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Identify OVHcloud via metadata server",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL, httpmock.NewJsonResponderOrPanic(200, metadataResponse{
+					AvailabilityZone: "GRA11",
+				}))
+			},
+			expectedProvider: identifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			o := &Ovh{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			go o.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			case <-time.After(time.Second):
+				t.Error("Identify() timed out")
+			}
+		})
+	}
+}
+
+func TestCheckVendorData(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedResult bool
+	}{
+		{
+			name:           "OVHcloud vendor data",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"ovh": {"region": "GRA11"}}`,
+			expectedResult: true,
+		},
+		{
+			name:           "Generic OpenStack vendor data",
+			responseStatus: http.StatusOK,
+			responseBody:   `{}`,
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusNotFound,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", vendorDataURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			o := &Ovh{}
+			logger := zap.NewNop()
+			result := o.checkVendorData(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorData() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name             string
+		availabilityZone string
+		expectedResult   bool
+	}{
+		{
+			name:             "OVHcloud region",
+			availabilityZone: "GRA11",
+			expectedResult:   true,
+		},
+		{
+			name:             "OVHcloud 3-AZ region",
+			availabilityZone: "eu-west-par-a",
+			expectedResult:   true,
+		},
+		{
+			name:             "Generic OpenStack availability zone",
+			availabilityZone: "nova",
+			expectedResult:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewJsonResponderOrPanic(200, metadataResponse{
+				AvailabilityZone: tt.availabilityZone,
+			}))
+
+			o := &Ovh{}
+			logger := zap.NewNop()
+			result := o.checkMetadataServer(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Vendor file contains OVH",
+			fileContent:    "OVH SAS",
+			expectedResult: true,
+		},
+		{
+			name:           "Vendor file does not contain OVH",
+			fileContent:    "OpenStack Foundation",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			o := &Ovh{}
+			logger := zap.NewNop()
+			result := o.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckProductNameFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Product name contains OVH",
+			fileContent:    "OVHcloud Public Cloud",
+			expectedResult: true,
+		},
+		{
+			name:           "Product name does not contain OVH",
+			fileContent:    "OpenStack Nova",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			o := &Ovh{}
+			logger := zap.NewNop()
+			result := o.checkProductNameFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkProductNameFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
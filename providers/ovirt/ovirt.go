@@ -0,0 +1,72 @@
+// Package ovirt implements the oVirt (and Red Hat Virtualization) private virtualization platform detection.
+package ovirt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	vendorFile      = "/sys/class/dmi/id/sys_vendor"
+	productNameFile = "/sys/class/dmi/id/product_name"
+	identifier      = types.Ovirt
+)
+
+// productNames are the product names oVirt and its downstream Red Hat Virtualization set in the SMBIOS tables.
+var productNames = []string{"oVirt Node", "RHEV Hypervisor", "RHV"}
+
+type Ovirt struct{}
+
+func (o *Ovirt) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (o *Ovirt) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return o.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, productNameFile); probe.File(ctx, identifier, "product_name file", file, func() bool { return o.checkProductNameFile(file, logger) }) {
+		ch <- types.Evidence{Provider: o.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+func (o *Ovirt) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "oVirt"
+}
+
+func (o *Ovirt) checkProductNameFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s product name file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	for _, name := range productNames {
+		if strings.HasPrefix(strings.TrimSpace(string(content)), name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package ovirt
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	o := &Ovirt{}
+	if o.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", o.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify oVirt via vendor file",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "oVirt\n",
+				"sys/class/dmi/id/product_name": "oVirt Node\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Identify Red Hat Virtualization via product name file",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "Red Hat\n",
+				"sys/class/dmi/id/product_name": "RHEV Hypervisor\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Other vendor",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "Red Hat\n",
+				"sys/class/dmi/id/product_name": "KVM\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.CreateRoot(t, tt.files)
+
+			o := &Ovirt{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			o.Identify(context.Background(), ch, root, logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			default:
+				if tt.expectedProvider != types.Unknown {
+					t.Error("Identify() reported no evidence")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "oVirt vendor",
+			fileContent:    "oVirt\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "Red Hat",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			o := &Ovirt{}
+			logger := zap.NewNop()
+			result := o.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckProductNameFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "oVirt Node product",
+			fileContent:    "oVirt Node\n",
+			expectedResult: true,
+		},
+		{
+			name:           "RHV product",
+			fileContent:    "RHV\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other product",
+			fileContent:    "OpenStack Nova",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			o := &Ovirt{}
+			logger := zap.NewNop()
+			result := o.checkProductNameFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkProductNameFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
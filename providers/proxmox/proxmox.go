@@ -0,0 +1,82 @@
+// Package proxmox implements the Proxmox VE private virtualization platform detection.
+//
+// Proxmox VE virtual machines report QEMU as their system vendor, like any other QEMU/KVM guest.
+// They are only identified when their SMBIOS strings (set with the smbios1 option of the VM) name Proxmox.
+package proxmox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	vendorFile = "/sys/class/dmi/id/sys_vendor"
+	identifier = types.Proxmox
+)
+
+// smbiosFiles are the DMI files holding the SMBIOS system and baseboard strings a Proxmox VE VM can set.
+var smbiosFiles = []string{
+	"/sys/class/dmi/id/sys_vendor",
+	"/sys/class/dmi/id/product_name",
+	"/sys/class/dmi/id/product_family",
+	"/sys/class/dmi/id/product_version",
+	"/sys/class/dmi/id/board_vendor",
+}
+
+type Proxmox struct{}
+
+func (p *Proxmox) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (p *Proxmox) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	files := make([]string, len(smbiosFiles))
+	for i, file := range smbiosFiles {
+		files[i] = filepath.Join(root, file)
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return p.checkVendorFile(file, logger) }) &&
+		probe.Run(ctx, identifier, "smbios files", strings.Join(files, ", "), func() bool { return p.checkSMBIOSFiles(files, logger) }) {
+		ch <- types.Evidence{Provider: p.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+func (p *Proxmox) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	vendor := strings.TrimSpace(string(content))
+	return vendor == "QEMU" || strings.Contains(strings.ToLower(vendor), "proxmox")
+}
+
+func (p *Proxmox) checkSMBIOSFiles(files []string, logger *zap.Logger) bool {
+	for _, file := range files {
+		logger.Debug(fmt.Sprintf("Checking %s SMBIOS file %s", identifier, file))
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(string(content)), "proxmox") {
+			return true
+		}
+	}
+
+	return false
+}
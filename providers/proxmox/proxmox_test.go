@@ -0,0 +1,130 @@
+package proxmox
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	p := &Proxmox{}
+	if p.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", p.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify Proxmox via SMBIOS product name",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "QEMU\n",
+				"sys/class/dmi/id/product_name": "Proxmox VE VM\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Identify Proxmox via SMBIOS vendor",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor": "Proxmox Server Solutions GmbH\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Plain QEMU guest",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor":   "QEMU\n",
+				"sys/class/dmi/id/product_name": "Standard PC (Q35 + ICH9, 2009)\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.CreateRoot(t, tt.files)
+
+			p := &Proxmox{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			p.Identify(context.Background(), ch, root, logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			default:
+				if tt.expectedProvider != types.Unknown {
+					t.Error("Identify() reported no evidence")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "QEMU vendor",
+			fileContent:    "QEMU\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "VMware, Inc.",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			p := &Proxmox{}
+			logger := zap.NewNop()
+			result := p.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
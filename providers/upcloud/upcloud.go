@@ -0,0 +1,107 @@
+// Package upcloud implements the UpCloud cloud provider detection.
+package upcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	// metadataURL is shared with DigitalOcean, so the response is only accepted if it names UpCloud.
+	metadataURL string = "http://169.254.169.254/metadata/v1.json"
+	vendorFile         = "/sys/class/dmi/id/sys_vendor"
+	identifier         = types.UpCloud
+)
+
+type metadataResponse struct {
+	CloudName  string `json:"cloud_name"`
+	InstanceID string `json:"instance_id"`
+}
+
+type UpCloud struct{}
+
+func (u *UpCloud) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (u *UpCloud) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: u.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return u.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: u.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return u.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: u.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: u.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+func (u *UpCloud) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return false
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response: %s", err))
+		return false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return false
+	}
+
+	metadata := new(metadataResponse)
+	if decodeErr := json.NewDecoder(resp.Body).Decode(metadata); decodeErr != nil {
+		logger.Error(fmt.Sprintf("Error decoding response: %s", decodeErr))
+		return false
+	}
+
+	return metadata.CloudName == "upcloud" && strings.TrimSpace(metadata.InstanceID) != ""
+}
+
+func (u *UpCloud) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(string(content), "UpCloud")
+}
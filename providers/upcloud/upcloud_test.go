@@ -0,0 +1,158 @@
+package upcloud
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "vendorfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		setupMocks       func()
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify UpCloud via metadata server",
+			setupMocks: func() {
+				httpmock.RegisterResponder("GET", metadataURL, httpmock.NewJsonResponderOrPanic(200, metadataResponse{
+					CloudName:  "upcloud",
+					InstanceID: "00a1b2c3-d4e5-f678-9012-3456789abcde",
+				}))
+			},
+			expectedProvider: identifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+			tt.setupMocks()
+
+			u := &UpCloud{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			go u.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			case <-time.After(time.Second):
+				t.Error("Identify() timed out")
+			}
+		})
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedResult bool
+	}{
+		{
+			name:           "UpCloud metadata response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"cloud_name": "upcloud", "instance_id": "00a1b2c3-d4e5-f678-9012-3456789abcde"}`,
+			expectedResult: true,
+		},
+		{
+			name:           "DigitalOcean metadata response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"droplet_id": 12345678, "hostname": "droplet"}`,
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusInternalServerError,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			u := &UpCloud{}
+			logger := zap.NewNop()
+			result := u.checkMetadataServer(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Vendor file contains UpCloud",
+			fileContent:    "UpCloud",
+			expectedResult: true,
+		},
+		{
+			name:           "Vendor file does not contain UpCloud",
+			fileContent:    "DigitalOcean",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			u := &UpCloud{}
+			logger := zap.NewNop()
+			result := u.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package vmware implements the VMware vSphere private virtualization platform detection.
+package vmware
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	vendorFile = "/sys/class/dmi/id/sys_vendor"
+	identifier = types.Vmware
+)
+
+type Vmware struct{}
+
+func (v *Vmware) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (v *Vmware) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return v.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	// VMware Workstation and Fusion assign addresses from the same OUIs, so a match is only a hint.
+	var confidence types.Confidence
+	if pattern := filepath.Join(root, network.MACAddressFiles); probe.Run(ctx, identifier, "mac address files", pattern, func() (ok bool) {
+		confidence, ok = v.checkMACAddressFiles(pattern, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: confidence}
+		return
+	}
+}
+
+func (v *Vmware) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.TrimSpace(string(content)) == "VMware, Inc."
+}
+
+func (v *Vmware) checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Confidence, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s MAC address files %s", identifier, pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	vendor, ok := network.MatchMAC(identifier, addrs)
+	return vendor.Confidence, ok
+}
@@ -0,0 +1,177 @@
+package vmware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentifier(t *testing.T) {
+	v := &Vmware{}
+	if v.Identifier() != identifier {
+		t.Errorf("Identifier() = %v; want %v", v.Identifier(), identifier)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedProvider types.ProviderId
+	}{
+		{
+			name: "Identify VMware via vendor file",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor": "VMware, Inc.\n",
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Identify VMware via cloud-init instance data",
+			files: map[string]string{
+				"run/cloud-init/instance-data.json": `{"v1": {"cloud_name": "vmware", "instance_id": "vm-42"}}`,
+			},
+			expectedProvider: identifier,
+		},
+		{
+			name: "Other cloud-init datasource",
+			files: map[string]string{
+				"run/cloud-init/ds-identify.log": "Found single datasource: NoCloud\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+		{
+			name: "Other vendor",
+			files: map[string]string{
+				"sys/class/dmi/id/sys_vendor": "QEMU\n",
+			},
+			expectedProvider: types.Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testutil.CreateRoot(t, tt.files)
+
+			v := &Vmware{}
+			ch := make(chan types.Evidence, 1)
+			logger := zap.NewNop()
+
+			v.Identify(context.Background(), ch, root, logger)
+
+			select {
+			case result := <-ch:
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
+				}
+			default:
+				if tt.expectedProvider != types.Unknown {
+					t.Error("Identify() reported no evidence")
+				}
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "VMware vendor",
+			fileContent:    "VMware, Inc.\n",
+			expectedResult: true,
+		},
+		{
+			name:           "Other vendor",
+			fileContent:    "Dell Inc.",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			v := &Vmware{}
+			logger := zap.NewNop()
+			result := v.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckMACAddressFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		address            string
+		expectedConfidence types.Confidence
+		expectedResult     bool
+	}{
+		{
+			name:               "VMware MAC address",
+			address:            "00:50:56:aa:bb:cc",
+			expectedConfidence: types.LowConfidence,
+			expectedResult:     true,
+		},
+		{
+			name:           "Other MAC address",
+			address:        "00:15:5d:aa:bb:cc",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "eth0", "device"), 0o755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "eth0", "address"), []byte(tt.address+"\n"), 0o644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			v := &Vmware{}
+			logger := zap.NewNop()
+			confidence, result := v.checkMACAddressFiles(filepath.Join(dir, "*", "address"), logger)
+
+			if confidence != tt.expectedConfidence || result != tt.expectedResult {
+				t.Errorf("checkMACAddressFiles() = %v, %v; want %v, %v", confidence, result, tt.expectedConfidence, tt.expectedResult)
+			}
+		})
+	}
+}
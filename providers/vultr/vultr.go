@@ -8,10 +8,13 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/cloudinit"
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -31,14 +34,24 @@ func (v *Vultr) Identifier() types.ProviderId {
 	return identifier
 }
 
-func (v *Vultr) Identify(ctx context.Context, ch chan<- types.ProviderId, logger *zap.Logger) {
-	if v.checkMetadataServer(ctx, logger) {
-		ch <- v.Identifier()
+func (v *Vultr) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	if instanceData, ok := cloudinit.MatchInstanceData(ctx, identifier, root, logger); ok {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.HighConfidence, Metadata: instanceData}
 		return
 	}
 
-	if v.checkVendorFile(vendorFile, logger) {
-		ch <- v.Identifier()
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) bool { return v.checkMetadataServer(ctx, logger) }) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.HighConfidence}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return v.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+
+	if cloudinit.MatchDSIdentify(ctx, identifier, root, logger) {
+		ch <- types.Evidence{Provider: v.Identifier(), Confidence: types.MediumConfidence}
 		return
 	}
 }
@@ -46,7 +59,7 @@ func (v *Vultr) Identify(ctx context.Context, ch chan<- types.ProviderId, logger
 func (v *Vultr) checkMetadataServer(ctx context.Context, logger *zap.Logger) bool {
 	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
 
-	client := &http.Client{}
+	client := probe.Client(ctx)
 	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Error creating request: %s", err))
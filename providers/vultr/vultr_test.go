@@ -10,6 +10,7 @@ import (
 	"github.com/jarcoal/httpmock"
 	"go.uber.org/zap"
 
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
 	"github.com/nikhil-prabhu/clouddetect/v2/types"
 )
 
@@ -54,15 +55,15 @@ func TestIdentify(t *testing.T) {
 			defer httpmock.DeactivateAndReset()
 
 			v := &Vultr{}
-			ch := make(chan types.ProviderId, 1)
+			ch := make(chan types.Evidence, 1)
 			logger := zap.NewNop()
 
-			go v.Identify(context.Background(), ch, logger)
+			go v.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
 
 			select {
 			case result := <-ch:
-				if result != tt.expectedProvider {
-					t.Errorf("Identify() = %v; want %v", result, tt.expectedProvider)
+				if result.Provider != tt.expectedProvider {
+					t.Errorf("Identify() = %v; want %v", result.Provider, tt.expectedProvider)
 				}
 			case <-time.After(time.Second):
 				t.Error("Identify() timed out")
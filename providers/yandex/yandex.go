@@ -0,0 +1,118 @@
+// Package yandex implements the Yandex Cloud provider detection.
+//
+// Yandex Compute Cloud serves a GCP-compatible metadata API, so the metadata check relies on the
+// Yandex-specific zone names rather than on the metadata server being reachable.
+package yandex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/probe"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	metadataURL string = "http://169.254.169.254/computeMetadata/v1/instance/zone"
+	vendorFile         = "/sys/class/dmi/id/sys_vendor"
+	identifier         = types.Yandex
+)
+
+var regions = []string{"ru-central1", "kz1"}
+
+type Yandex struct{}
+
+func (y *Yandex) Identifier() types.ProviderId {
+	return identifier
+}
+
+func (y *Yandex) Identify(ctx context.Context, ch chan<- types.Evidence, root string, logger *zap.Logger) {
+	var zone string
+	if probe.Request(ctx, identifier, "metadata server", metadataURL, func(ctx context.Context) (ok bool) {
+		zone, ok = y.checkMetadataServer(ctx, logger)
+		return ok
+	}) {
+		ch <- types.Evidence{
+			Provider:   y.Identifier(),
+			Confidence: types.HighConfidence,
+			Metadata:   types.Metadata{Region: regionOf(zone), Zone: zone},
+		}
+		return
+	}
+
+	if file := filepath.Join(root, vendorFile); probe.File(ctx, identifier, "sys_vendor file", file, func() bool { return y.checkVendorFile(file, logger) }) {
+		ch <- types.Evidence{Provider: y.Identifier(), Confidence: types.MediumConfidence}
+		return
+	}
+}
+
+// checkMetadataServer returns the zone of the instance if the metadata server reports a Yandex Cloud zone.
+func (y *Yandex) checkMetadataServer(ctx context.Context, logger *zap.Logger) (string, bool) {
+	logger.Debug(fmt.Sprintf("Checking %s metadata using url %s", identifier, metadataURL))
+
+	client := probe.Client(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error creating request: %s", err))
+		return "", false
+	}
+	req.Header.Add("Metadata-Flavor", "Google")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error sending request: %s", err))
+		return "", false
+	}
+	defer func(Body io.ReadCloser) {
+		closeErr := Body.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing response body: %s", closeErr))
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Error(fmt.Sprintf("Error response status code: %d", resp.StatusCode))
+		return "", false
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading response body: %s", err))
+		return "", false
+	}
+
+	// The zone is reported as projects/<folder-id>/zones/<zone>.
+	value := strings.TrimSpace(string(text))
+	zone := value[strings.LastIndex(value, "/")+1:]
+
+	return zone, strings.Contains(value, "/zones/") && slices.Contains(regions, regionOf(zone))
+}
+
+func (y *Yandex) checkVendorFile(file string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking %s vendor file %s", identifier, file))
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading file: %s", err))
+		return false
+	}
+
+	return strings.Contains(string(content), "Yandex")
+}
+
+// regionOf returns the region of a zone such as ru-central1-a.
+func regionOf(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+
+	return zone
+}
@@ -0,0 +1,143 @@
+package yandex
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/internal/testutil"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "vendorfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestIdentify(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", metadataURL,
+		httpmock.NewStringResponder(http.StatusOK, "projects/b1g2h3j4k5l6m7n8p9q0/zones/ru-central1-b"))
+
+	y := &Yandex{}
+	ch := make(chan types.Evidence, 1)
+	logger := zap.NewNop()
+
+	go y.Identify(context.Background(), ch, testutil.CreateRoot(t, nil), logger)
+
+	select {
+	case result := <-ch:
+		if result.Provider != identifier {
+			t.Errorf("Identify() = %v; want %v", result.Provider, identifier)
+		}
+		want := types.Metadata{Region: "ru-central1", Zone: "ru-central1-b"}
+		if result.Metadata != want {
+			t.Errorf("Identify() metadata = %+v; want %+v", result.Metadata, want)
+		}
+	case <-time.After(time.Second):
+		t.Error("Identify() timed out")
+	}
+}
+
+func TestCheckMetadataServer(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedResult bool
+	}{
+		{
+			name:           "Yandex Cloud zone",
+			responseStatus: http.StatusOK,
+			responseBody:   "projects/b1g2h3j4k5l6m7n8p9q0/zones/ru-central1-a",
+			expectedResult: true,
+		},
+		{
+			name:           "GCP zone",
+			responseStatus: http.StatusOK,
+			responseBody:   "projects/123456789/zones/us-central1-a",
+			expectedResult: false,
+		},
+		{
+			name:           "Non-OK status code",
+			responseStatus: http.StatusNotFound,
+			responseBody:   "",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpmock.RegisterResponder("GET", metadataURL, httpmock.NewStringResponder(tt.responseStatus, tt.responseBody))
+
+			y := &Yandex{}
+			logger := zap.NewNop()
+			_, result := y.checkMetadataServer(context.Background(), logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkMetadataServer() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckVendorFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		fileContent    string
+		expectedResult bool
+	}{
+		{
+			name:           "Vendor file contains Yandex",
+			fileContent:    "Yandex",
+			expectedResult: true,
+		},
+		{
+			name:           "Vendor file does not contain Yandex",
+			fileContent:    "Google",
+			expectedResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			y := &Yandex{}
+			logger := zap.NewNop()
+			result := y.checkVendorFile(tmpFile, logger)
+
+			if result != tt.expectedResult {
+				t.Errorf("checkVendorFile() = %v; want %v", result, tt.expectedResult)
+			}
+		})
+	}
+}
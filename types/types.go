@@ -9,9 +9,121 @@ const (
 	Alibaba      ProviderId = "alibaba"      // Alibaba is the Alibaba Cloud service provider.
 	Aws          ProviderId = "aws"          // Aws is the Amazon Web Services cloud service provider.
 	Azure        ProviderId = "azure"        // Azure is the Microsoft Azure cloud service provider.
+	CloudStack   ProviderId = "cloudstack"   // CloudStack is the Apache CloudStack cloud service provider.
 	DigitalOcean ProviderId = "digitalocean" // DigitalOcean is the DigitalOcean cloud service provider.
+	Equinix      ProviderId = "equinix"      // Equinix is the Equinix Metal cloud service provider.
+	Exoscale     ProviderId = "exoscale"     // Exoscale is the Exoscale cloud service provider.
 	Gcp          ProviderId = "gcp"          // Gcp is the Google Cloud Platform cloud service provider.
 	Oci          ProviderId = "oci"          // Oci is the Oracle Cloud Infrastructure cloud service provider.
 	OpenStack    ProviderId = "openstack"    // OpenStack is the OpenStack cloud service provider.
+	Ovh          ProviderId = "ovh"          // Ovh is the OVHcloud Public Cloud service provider.
+	UpCloud      ProviderId = "upcloud"      // UpCloud is the UpCloud cloud service provider.
 	Vultr        ProviderId = "vultr"        // Vultr is the Vultr cloud service provider.
+	Yandex       ProviderId = "yandex"       // Yandex is the Yandex Cloud service provider.
+	Hyperv       ProviderId = "hyperv"       // Hyperv is the Microsoft Hyper-V private virtualization platform.
+	Nutanix      ProviderId = "nutanix"      // Nutanix is the Nutanix AHV private virtualization platform.
+	Ovirt        ProviderId = "ovirt"        // Ovirt is the oVirt (and Red Hat Virtualization) private virtualization platform.
+	Proxmox      ProviderId = "proxmox"      // Proxmox is the Proxmox VE private virtualization platform.
+	Vmware       ProviderId = "vmware"       // Vmware is the VMware vSphere private virtualization platform.
 )
+
+// Platform is a managed runtime of a cloud service provider, such as a serverless function or container service.
+type Platform string
+
+const (
+	NoPlatform     Platform = ""                // NoPlatform is used when the host does not run in a managed runtime.
+	Lambda         Platform = "lambda"          // Lambda is AWS Lambda.
+	Fargate        Platform = "fargate"         // Fargate is Amazon ECS on AWS Fargate.
+	Ecs            Platform = "ecs"             // Ecs is Amazon ECS on EC2 container instances.
+	CloudRun       Platform = "cloud-run"       // CloudRun is Google Cloud Run (services and jobs).
+	CloudFunctions Platform = "cloud-functions" // CloudFunctions is Google Cloud Functions.
+	AppEngine      Platform = "app-engine"      // AppEngine is Google App Engine.
+	AzureFunctions Platform = "azure-functions" // AzureFunctions is Azure Functions.
+	AppService     Platform = "app-service"     // AppService is Azure App Service.
+	ContainerApps  Platform = "container-apps"  // ContainerApps is Azure Container Apps.
+)
+
+// KubernetesPlatform is a Kubernetes distribution, usually a managed Kubernetes service of a cloud service provider.
+type KubernetesPlatform string
+
+const (
+	NoKubernetes KubernetesPlatform = ""           // NoKubernetes is used when the host does not run in a Kubernetes pod.
+	Kubernetes   KubernetesPlatform = "kubernetes" // Kubernetes is a Kubernetes cluster whose distribution is not recognized.
+	Eks          KubernetesPlatform = "eks"        // Eks is Amazon Elastic Kubernetes Service.
+	Gke          KubernetesPlatform = "gke"        // Gke is Google Kubernetes Engine.
+	Aks          KubernetesPlatform = "aks"        // Aks is Azure Kubernetes Service.
+	Oke          KubernetesPlatform = "oke"        // Oke is Oracle Container Engine for Kubernetes.
+	Doks         KubernetesPlatform = "doks"       // Doks is DigitalOcean Kubernetes.
+	Lke          KubernetesPlatform = "lke"        // Lke is Akamai (Linode) Kubernetes Engine.
+)
+
+// ContainerRuntime is a container or sandbox runtime.
+type ContainerRuntime string
+
+const (
+	NoContainer ContainerRuntime = ""           // NoContainer is used when the host does not run in a container.
+	Container   ContainerRuntime = "container"  // Container is a container whose runtime is not recognized.
+	Docker      ContainerRuntime = "docker"     // Docker is the Docker Engine.
+	Podman      ContainerRuntime = "podman"     // Podman is Podman.
+	Containerd  ContainerRuntime = "containerd" // Containerd is containerd, including its CRI plugin.
+	CriO        ContainerRuntime = "cri-o"      // CriO is CRI-O.
+	Lxc         ContainerRuntime = "lxc"        // Lxc is LXC (and LXD/Incus) system containers.
+	GVisor      ContainerRuntime = "gvisor"     // GVisor is the gVisor application kernel sandbox.
+	Kata        ContainerRuntime = "kata"       // Kata is the Kata Containers lightweight VM sandbox.
+)
+
+// Virtualization is the hypervisor (or lack of one) the host runs on.
+type Virtualization string
+
+const (
+	UnknownVirtualization Virtualization = ""            // UnknownVirtualization is used when the virtualization could not be determined.
+	BareMetal             Virtualization = "bare-metal"  // BareMetal is a host that does not run on a hypervisor.
+	VirtualMachine        Virtualization = "vm"          // VirtualMachine is a virtual machine whose hypervisor is not recognized.
+	Nitro                 Virtualization = "nitro"       // Nitro is the AWS Nitro hypervisor.
+	Xen                   Virtualization = "xen"         // Xen is the Xen hypervisor.
+	Kvm                   Virtualization = "kvm"         // Kvm is KVM, usually with QEMU as the virtual machine monitor.
+	HyperV                Virtualization = "hyper-v"     // HyperV is Microsoft Hyper-V.
+	VMware                Virtualization = "vmware"      // VMware is VMware ESXi.
+	Firecracker           Virtualization = "firecracker" // Firecracker is the Firecracker microVM monitor.
+)
+
+// Confidence indicates how strongly a piece of evidence identifies a cloud service provider.
+type Confidence int
+
+const (
+	NoConfidence     Confidence = iota // NoConfidence is the confidence of an empty detection result.
+	LowConfidence                      // LowConfidence is a hint that other hosts may share, such as a server vendor.
+	MediumConfidence                   // MediumConfidence is a local signal specific to a provider, such as a DMI vendor string.
+	HighConfidence                     // HighConfidence is a signal served by the provider itself, such as its metadata service.
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case LowConfidence:
+		return "low"
+	case MediumConfidence:
+		return "medium"
+	case HighConfidence:
+		return "high"
+	default:
+		return "none"
+	}
+}
+
+// Metadata is instance metadata normalized across cloud service providers.
+//
+// Fields are left empty when the provider does not expose them or the check that matched did not read them.
+type Metadata struct {
+	InstanceID string // InstanceID is the provider's identifier for the host.
+	Region     string // Region is the provider region (or metro) the host runs in.
+	Zone       string // Zone is the availability zone (or facility) the host runs in.
+	Project    string // Project is the project (or account) the host belongs to.
+}
+
+// Evidence is reported by a provider when one of its checks matches the host.
+type Evidence struct {
+	Provider   ProviderId // Provider is the cloud service provider the evidence points to.
+	Confidence Confidence // Confidence is how strongly the evidence identifies the provider.
+	Platform   Platform   // Platform is the managed runtime the host runs in, if any.
+	Metadata   Metadata   // Metadata is the normalized metadata gathered by the check, if any.
+}
@@ -0,0 +1,224 @@
+// Package virtualization implements detection of the hypervisor the host runs on.
+//
+// All files are read relative to a root directory, so the detection can run against a copy of
+// another host's filesystem (or test fixtures) as well as against "/".
+package virtualization
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/network"
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+const (
+	hypervisorTypeFile = "/sys/hypervisor/type"
+	hypervisorUUIDFile = "/sys/hypervisor/uuid"
+	vendorFile         = "/sys/class/dmi/id/sys_vendor"
+	productNameFile    = "/sys/class/dmi/id/product_name"
+	cpuInfoFile        = "/proc/cpuinfo"
+	pciVendorFiles     = "/sys/bus/pci/devices/*/vendor"
+	platformDevices    = "/sys/bus/platform/devices/*"
+	cmdlineFile        = "/proc/cmdline"
+)
+
+// virtioMMIODevices are the name fragments of virtio-mmio platform devices: virtio-mmio.<n> when declared on
+// the kernel command line, <address>.virtio_mmio in a device tree, and LNRO0005:<n> in ACPI tables.
+var virtioMMIODevices = []string{"virtio-mmio", "virtio_mmio", "LNRO0005"}
+
+// pciVendors are the PCI vendor IDs of the emulated and paravirtualized devices of each hypervisor.
+// They are checked in order, so the vendors that only appear on a single hypervisor come first.
+var pciVendors = []struct {
+	id             string
+	virtualization types.Virtualization
+}{
+	{"0x1d0f", types.Nitro},  // Amazon (ENA and EBS NVMe)
+	{"0x15ad", types.VMware}, // VMware
+	{"0x1414", types.HyperV}, // Microsoft
+	{"0x5853", types.Xen},    // XenSource
+	{"0x1af4", types.Kvm},    // Red Hat (virtio)
+}
+
+// Detect returns the hypervisor the host runs on, reading all files under root.
+func Detect(root string, logger *zap.Logger) types.Virtualization {
+	if checkHypervisorFiles(filepath.Join(root, hypervisorTypeFile), filepath.Join(root, hypervisorUUIDFile), logger) {
+		return types.Xen
+	}
+
+	vendor, hasDMI := readFile(filepath.Join(root, vendorFile), logger)
+	product, _ := readFile(filepath.Join(root, productNameFile), logger)
+	if virtualization, ok := checkDMI(vendor, product); ok {
+		return virtualization
+	}
+
+	vendors := readPCIVendors(filepath.Join(root, pciVendorFiles), logger)
+	for _, v := range pciVendors {
+		if slices.Contains(vendors, v.id) {
+			return v.virtualization
+		}
+	}
+
+	virtual, ok := checkCPUInfoFile(filepath.Join(root, cpuInfoFile), logger)
+	switch {
+	case ok && !virtual:
+		return types.BareMetal
+	case ok && !hasDMI && len(vendors) == 0 && checkVirtioMMIO(root, logger):
+		// Firecracker exposes neither SMBIOS tables nor a PCI bus; its devices are all virtio-mmio. Containers
+		// with /sys masked and sandboxes such as gVisor expose neither either, but no virtio-mmio devices.
+		return types.Firecracker
+	}
+
+	if virtualization, found := checkMACAddressFiles(filepath.Join(root, network.MACAddressFiles), logger); found {
+		return virtualization
+	}
+
+	if !ok {
+		return types.UnknownVirtualization
+	}
+
+	return types.VirtualMachine
+}
+
+// checkMACAddressFiles returns the hypervisor that assigned the MAC address of a network device. It is only
+// checked once the host is known not to be bare metal, as hosts running virtual machines have interfaces
+// with these addresses too.
+func checkMACAddressFiles(pattern string, logger *zap.Logger) (types.Virtualization, bool) {
+	logger.Debug(fmt.Sprintf("Checking MAC address files %s", pattern))
+
+	addrs, err := network.ReadMACAddresses(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading MAC addresses: %s", err))
+	}
+
+	for _, addr := range addrs {
+		if vendor, ok := network.LookupMAC(addr); ok && vendor.Virtualization != types.UnknownVirtualization {
+			return vendor.Virtualization, true
+		}
+	}
+
+	return types.UnknownVirtualization, false
+}
+
+// checkHypervisorFiles checks the files the Xen guest drivers expose. They don't exist on other hypervisors.
+func checkHypervisorFiles(typeFile string, uuidFile string, logger *zap.Logger) bool {
+	logger.Debug(fmt.Sprintf("Checking hypervisor type file %s and uuid file %s", typeFile, uuidFile))
+
+	if content, ok := readFile(typeFile, logger); ok {
+		return content == "xen"
+	}
+
+	content, ok := readFile(uuidFile, logger)
+	return ok && content != ""
+}
+
+func checkDMI(vendor string, product string) (types.Virtualization, bool) {
+	switch {
+	case vendor == "Amazon EC2" && strings.HasSuffix(product, ".metal"):
+		return types.BareMetal, true
+	case vendor == "Amazon EC2":
+		return types.Nitro, true
+	case vendor == "Microsoft Corporation" && product == "Virtual Machine":
+		return types.HyperV, true
+	case vendor == "VMware, Inc." || strings.HasPrefix(product, "VMware"):
+		return types.VMware, true
+	case vendor == "Xen":
+		return types.Xen, true
+	case vendor == "QEMU" || strings.Contains(product, "KVM") || product == "Google Compute Engine":
+		return types.Kvm, true
+	default:
+		return types.UnknownVirtualization, false
+	}
+}
+
+// checkCPUInfoFile reports whether the CPU flags include the hypervisor flag, which x86 CPUs set
+// when running in a virtual machine. It returns false for the second value if no flags are listed,
+// as is the case on other architectures.
+func checkCPUInfoFile(file string, logger *zap.Logger) (bool, bool) {
+	logger.Debug(fmt.Sprintf("Checking CPU info file %s", file))
+
+	f, err := os.Open(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return false, false
+	}
+	defer func(f *os.File) {
+		closeErr := f.Close()
+		if closeErr != nil {
+			logger.Error(fmt.Sprintf("Error closing file: %s", closeErr))
+		}
+	}(f)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found && strings.TrimSpace(key) == "flags" {
+			return slices.Contains(strings.Fields(value), "hypervisor"), true
+		}
+	}
+
+	return false, false
+}
+
+// checkVirtioMMIO reports whether the host has virtio-mmio devices, listed as platform devices or passed on
+// the kernel command line (virtio_mmio.device=<size>@<address>:<irq>), as Firecracker does.
+func checkVirtioMMIO(root string, logger *zap.Logger) bool {
+	pattern := filepath.Join(root, platformDevices)
+	logger.Debug(fmt.Sprintf("Checking virtio-mmio platform devices %s", pattern))
+
+	devices, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+	}
+
+	for _, device := range devices {
+		name := filepath.Base(device)
+		if slices.ContainsFunc(virtioMMIODevices, func(fragment string) bool { return strings.Contains(name, fragment) }) {
+			return true
+		}
+	}
+
+	cmdline, _ := readFile(filepath.Join(root, cmdlineFile), logger)
+	for _, param := range strings.Fields(cmdline) {
+		if key, _, _ := strings.Cut(param, "="); key == "virtio_mmio.device" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readPCIVendors(pattern string, logger *zap.Logger) []string {
+	logger.Debug(fmt.Sprintf("Checking PCI vendor files %s", pattern))
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error matching files: %s", err))
+		return nil
+	}
+
+	var vendors []string
+	for _, file := range files {
+		if vendor, ok := readFile(file, logger); ok {
+			vendors = append(vendors, vendor)
+		}
+	}
+
+	return vendors
+}
+
+func readFile(file string, logger *zap.Logger) (string, bool) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error reading file: %s", err))
+		return "", false
+	}
+
+	return strings.TrimSpace(string(content)), true
+}
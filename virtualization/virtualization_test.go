@@ -0,0 +1,152 @@
+package virtualization
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nikhil-prabhu/clouddetect/v2/types"
+)
+
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "testfile-*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, writeErr := tmpFile.WriteString(content); writeErr != nil {
+		t.Fatalf("Failed to write to temp file: %v", writeErr)
+	}
+
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		t.Fatalf("Failed to close temp file: %v", closeErr)
+	}
+
+	return tmpFile.Name()
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name                   string
+		root                   string
+		expectedVirtualization types.Virtualization
+	}{
+		{name: "AWS Nitro instance", root: "nitro", expectedVirtualization: types.Nitro},
+		{name: "AWS bare metal instance", root: "metal", expectedVirtualization: types.BareMetal},
+		{name: "Xen guest", root: "xen", expectedVirtualization: types.Xen},
+		{name: "Hyper-V guest", root: "hyperv", expectedVirtualization: types.HyperV},
+		{name: "VMware guest", root: "vmware", expectedVirtualization: types.VMware},
+		{name: "KVM guest with virtio devices", root: "kvm", expectedVirtualization: types.Kvm},
+		{name: "Firecracker microVM", root: "firecracker", expectedVirtualization: types.Firecracker},
+		{name: "Firecracker microVM with ACPI", root: "firecrackeracpi", expectedVirtualization: types.Firecracker},
+		{name: "Virtual machine without DMI, PCI or virtio-mmio devices", root: "sandbox", expectedVirtualization: types.VirtualMachine},
+		{name: "Unrecognized hypervisor", root: "vm", expectedVirtualization: types.VirtualMachine},
+		{name: "Hypervisor recognized from the MAC address", root: "xenmac", expectedVirtualization: types.Xen},
+		{name: "Hypervisor MAC address on a virtual interface", root: "bridge", expectedVirtualization: types.VirtualMachine},
+		{name: "Bare metal server", root: "baremetal", expectedVirtualization: types.BareMetal},
+		{name: "Architecture without CPU flags", root: "arm", expectedVirtualization: types.UnknownVirtualization},
+		{name: "Missing root", root: "missing", expectedVirtualization: types.UnknownVirtualization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			result := Detect(filepath.Join("testdata", tt.root), logger)
+
+			if result != tt.expectedVirtualization {
+				t.Errorf("Detect() = %v; want %v", result, tt.expectedVirtualization)
+			}
+		})
+	}
+}
+
+func TestCheckDMI(t *testing.T) {
+	tests := []struct {
+		name                   string
+		vendor                 string
+		product                string
+		expectedVirtualization types.Virtualization
+		expectedResult         bool
+	}{
+		{
+			name:                   "QEMU",
+			vendor:                 "QEMU",
+			product:                "Standard PC (Q35 + ICH9, 2009)",
+			expectedVirtualization: types.Kvm,
+			expectedResult:         true,
+		},
+		{
+			name:                   "Google Compute Engine",
+			vendor:                 "Google",
+			product:                "Google Compute Engine",
+			expectedVirtualization: types.Kvm,
+			expectedResult:         true,
+		},
+		{
+			name:                   "Microsoft hardware",
+			vendor:                 "Microsoft Corporation",
+			product:                "Surface Laptop 5",
+			expectedVirtualization: types.UnknownVirtualization,
+			expectedResult:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			virtualization, result := checkDMI(tt.vendor, tt.product)
+
+			if virtualization != tt.expectedVirtualization || result != tt.expectedResult {
+				t.Errorf("checkDMI() = %v, %v; want %v, %v", virtualization, result, tt.expectedVirtualization, tt.expectedResult)
+			}
+		})
+	}
+}
+
+func TestCheckCPUInfoFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileContent     string
+		expectedVirtual bool
+		expectedResult  bool
+	}{
+		{
+			name:            "Hypervisor flag",
+			fileContent:     "processor\t: 0\nflags\t\t: fpu vme de hypervisor lahf_lm\n",
+			expectedVirtual: true,
+			expectedResult:  true,
+		},
+		{
+			name:            "No hypervisor flag",
+			fileContent:     "processor\t: 0\nflags\t\t: fpu vme de lahf_lm\n",
+			expectedVirtual: false,
+			expectedResult:  true,
+		},
+		{
+			name:            "No flags",
+			fileContent:     "processor\t: 0\nFeatures\t: fp asimd\n",
+			expectedVirtual: false,
+			expectedResult:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := createTempFile(t, tt.fileContent)
+			defer func(name string) {
+				err := os.Remove(name)
+				if err != nil {
+					t.Fatalf("Failed to remove temp file: %v", err)
+				}
+			}(tmpFile)
+
+			logger := zap.NewNop()
+			virtual, result := checkCPUInfoFile(tmpFile, logger)
+
+			if virtual != tt.expectedVirtual || result != tt.expectedResult {
+				t.Errorf("checkCPUInfoFile() = %v, %v; want %v, %v", virtual, result, tt.expectedVirtual, tt.expectedResult)
+			}
+		})
+	}
+}